@@ -0,0 +1,231 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Bionic2113/errgen/internal/prcs"
+	"github.com/Bionic2113/errgen/internal/verify"
+	"github.com/Bionic2113/errgen/internal/vfs"
+	"github.com/Bionic2113/errgen/pkg/skipper"
+	"github.com/Bionic2113/errgen/pkg/stringer"
+	"github.com/Bionic2113/errgen/pkg/utils"
+)
+
+func generate(args []string) int {
+	opts := &options{}
+	fs := newFlagSet("generate", opts)
+	dryRun := fs.Bool("dry-run", false, "print unified diff of all changes instead of writing files")
+	verifyTypes := fs.Bool("verify", true, "type-check the result before writing files")
+	if err := fs.Parse(args); err != nil {
+		return flagExitCode(err)
+	}
+
+	cfg, err := opts.load(fs)
+	if err != nil {
+		return fail(err)
+	}
+
+	// All changes are staged in memory and written only
+	// when the whole result is consistent
+	overlay := vfs.NewOverlay()
+	processor, err := newProcessor(cfg, opts, overlay)
+	if err != nil {
+		return fail(err)
+	}
+
+	if err := processor.ProcessFiles(); err != nil {
+		return fail(err)
+	}
+
+	genErr := processor.GenerateErrorFiles()
+	if genErr != nil && !*dryRun {
+		// Sources may already call constructors which were not generated
+		return fail(fmt.Errorf("nothing is written: %w", genErr))
+	}
+
+	if err := apply(overlay, opts.dir, *dryRun, *verifyTypes); err != nil {
+		return fail(err)
+	}
+
+	if genErr != nil {
+		fmt.Fprintln(os.Stderr, "errgen:", genErr)
+		return 1
+	}
+
+	return 0
+}
+
+// check runs generation in memory and fails when sources
+// have unwrapped returns or generated files are stale.
+func check(args []string) int {
+	opts := &options{}
+	fs := newFlagSet("check", opts)
+	if err := fs.Parse(args); err != nil {
+		return flagExitCode(err)
+	}
+
+	cfg, err := opts.load(fs)
+	if err != nil {
+		return fail(err)
+	}
+
+	overlay := vfs.NewOverlay()
+	processor, err := newProcessor(cfg, opts, overlay)
+	if err != nil {
+		return fail(err)
+	}
+
+	if err := processor.ProcessFiles(); err != nil {
+		return fail(err)
+	}
+
+	if err := processor.GenerateErrorFiles(); err != nil {
+		return fail(err)
+	}
+
+	changes, err := overlay.Changes()
+	if err != nil {
+		return fail(err)
+	}
+
+	var unwrapped, stale []prcs.Unwrapped
+	for _, u := range processor.Unwrapped() {
+		if u.Stale {
+			stale = append(stale, u)
+			continue
+		}
+		unwrapped = append(unwrapped, u)
+	}
+
+	for _, u := range unwrapped {
+		fmt.Printf("%s:%d: error return is not wrapped by %s\n", relPath(opts.dir, u.Pos.Filename), u.Pos.Line, u.Constructor)
+	}
+
+	for _, u := range stale {
+		fmt.Printf("%s:%d: wrapper call is stale, it would be migrated to %s\n", relPath(opts.dir, u.Pos.Filename), u.Pos.Line, u.Constructor)
+	}
+
+	for _, c := range changes {
+		fmt.Printf("%s: file would be %s\n", relPath(opts.dir, c.Path), c.Kind)
+	}
+
+	if len(unwrapped) > 0 || len(stale) > 0 || len(changes) > 0 {
+		fmt.Fprintf(os.Stderr, "errgen: %d unwrapped return(s), %d stale wrapper call(s), %d stale file(s)\n",
+			len(unwrapped), len(stale), len(changes))
+		return 1
+	}
+
+	return 0
+}
+
+// clean removes all errgen wrapping from sources.
+func clean(args []string) int {
+	opts := &options{}
+	fs := newFlagSet("clean", opts)
+	dryRun := fs.Bool("dry-run", false, "print unified diff of all changes instead of writing files")
+	verifyTypes := fs.Bool("verify", true, "type-check the result before writing files")
+	if err := fs.Parse(args); err != nil {
+		return flagExitCode(err)
+	}
+
+	cfg, err := opts.load(fs)
+	if err != nil {
+		return fail(err)
+	}
+
+	overlay := vfs.NewOverlay()
+	cleaner := prcs.NewCleaner(
+		opts.dir, opts.patterns,
+		cfg.SimpleErrFilename, cfg.WrapperFilename, cfg.Stringer.FileName,
+		overlay,
+	)
+	if err := cleaner.Clean(); err != nil {
+		return fail(err)
+	}
+
+	for _, w := range cleaner.Warnings() {
+		fmt.Fprintln(os.Stderr, "errgen: warning:", w)
+	}
+
+	if err := apply(overlay, opts.dir, *dryRun, *verifyTypes); err != nil {
+		return fail(err)
+	}
+
+	return 0
+}
+
+// apply prints staged changes in dry-run mode, otherwise verifies
+// and writes them. Nothing is written if verification fails.
+func apply(overlay *vfs.Overlay, dir string, dryRun, verifyTypes bool) error {
+	if dryRun {
+		return overlay.Diff(os.Stdout, dir)
+	}
+
+	changes, err := overlay.Changes()
+	if err != nil {
+		return err
+	}
+
+	if verifyTypes {
+		err := verify.Changes(dir, changes)
+		switch {
+		case errors.Is(err, verify.ErrTypesUnavailable):
+			fmt.Fprintln(os.Stderr, "errgen: warning: result is not type-checked:", err)
+		case err != nil:
+			return fmt.Errorf("nothing is written, result is broken: %w", err)
+		}
+	}
+
+	return overlay.Commit()
+}
+
+func newProcessor(cfg *Config, opts *options, fsys utils.FileSystem) (*prcs.FileProcessor, error) {
+	st, err := stringer.NewStringer(cfg.Stringer)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fields are redacted by generated String(), so the tag must be the same
+	cfg.Skipper.Redact.TagName = cfg.Stringer.TagName
+	sk, err := skipper.New(cfg.Skipper, opts.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return prcs.New(
+		opts.dir, opts.patterns,
+		cfg.SimpleErrFilename, cfg.WrapperFilename,
+		cfg.Generator,
+		fsys,
+		st,
+		sk,
+	)
+}
+
+func relPath(base, path string) string {
+	rel, err := filepath.Rel(base, path)
+	if err != nil {
+		return path
+	}
+
+	return rel
+}
+
+func fail(err error) int {
+	fmt.Fprintln(os.Stderr, "errgen:", err)
+	return 1
+}
+
+// flagExitCode converts flag parsing error to exit code,
+// the error itself is already printed by flag package.
+func flagExitCode(err error) int {
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+
+	return 2
+}
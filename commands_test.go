@@ -0,0 +1,55 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGenerateWritesNothingOnError(t *testing.T) {
+	dir := t.TempDir()
+	src := `package a
+
+import "errors"
+
+type GetError struct{}
+
+func Get(id int) error {
+	return errors.New("not found")
+}
+
+func Put(id int) error {
+	return errors.New("conflict")
+}
+`
+	files := map[string]string{
+		"go.mod": "module t\n\ngo 1.22\n",
+		"a/a.go": src,
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// GetError clashes with the declared type
+	if code := run([]string{"generate", "--dir", dir}); code != 1 {
+		t.Fatalf("errgen generate exited with code %d, want 1", code)
+	}
+
+	if got := readFile(t, filepath.Join(dir, "a", "a.go")); got != src {
+		t.Errorf("a/a.go is modified by the failed run:\n%s", got)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(dir, "a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("files are generated by the failed run: %v", entries)
+	}
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Bionic2113/errgen/internal/generator"
+	"github.com/Bionic2113/errgen/pkg/skipper"
+	"github.com/Bionic2113/errgen/pkg/stringer"
+	"github.com/ilyakaznacheev/cleanenv"
+)
+
+const defaultConfigName = ".errgen.yaml"
+
+type Config struct {
+	Skipper           skipper.Config   `yaml:"skipper"`
+	Stringer          stringer.Config  `yaml:"stringer"`
+	Generator         generator.Config `yaml:"generator"`
+	WrapperFilename   string           `yaml:"wrapper_filename" env-default:"errwrap_gen"`
+	SimpleErrFilename string           `yaml:"simple_err_filename" env-default:"error_gen"`
+}
+
+// options are the flags shared by all commands which work with sources.
+type options struct {
+	configPath string
+	dir        string
+	patterns   []string
+
+	// overrides are applied on top of the loaded config,
+	// only for flags which were set explicitly.
+	overrides map[string]func(cfg *Config)
+}
+
+func newFlagSet(name string, opts *options) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: errgen %s [flags] [packages]\n\nFlags:\n", name)
+		fs.PrintDefaults()
+	}
+
+	fs.StringVar(&opts.configPath, "config", "", "path to config file (default <dir>/"+defaultConfigName+")")
+	fs.StringVar(&opts.dir, "dir", ".", "root directory of the sources")
+
+	opts.overrides = make(map[string]func(cfg *Config))
+	override := func(name, usage string, apply func(cfg *Config, value string)) {
+		fs.Func(name, usage, func(value string) error {
+			opts.overrides[name] = func(cfg *Config) { apply(cfg, value) }
+			return nil
+		})
+	}
+
+	override("wrapper-filename", "name of generated wrappers file without extension",
+		func(cfg *Config, v string) { cfg.WrapperFilename = v })
+	override("simple-err-filename", "name of generated sentinel errors file without extension",
+		func(cfg *Config, v string) { cfg.SimpleErrFilename = v })
+	override("naming", "wrapper type name template, e.g. {{.Name}}Error",
+		func(cfg *Config, v string) { cfg.Generator.Naming = v })
+	override("is-strategy", "Is method of wrappers: type, reason, pointer or sentinel",
+		func(cfg *Config, v string) { cfg.Generator.IsStrategy = generator.IsStrategy(v) })
+	override("wrapper-template", "path to wrapper text/template file",
+		func(cfg *Config, v string) { cfg.Generator.WrapperTemplate = v })
+	override("sentinel-template", "path to sentinel errors text/template file",
+		func(cfg *Config, v string) { cfg.Generator.SentinelTemplate = v })
+	override("stringer-template", "path to String() text/template file",
+		func(cfg *Config, v string) { cfg.Stringer.Template = v })
+	override("stringer-filename", "name of generated String() file without extension",
+		func(cfg *Config, v string) { cfg.Stringer.FileName = v })
+	override("stringer-tagname", "struct tag used by stringer",
+		func(cfg *Config, v string) { cfg.Stringer.TagName = v })
+	override("stringer-separator", "separator between fields in String()",
+		func(cfg *Config, v string) { cfg.Stringer.Separator = v })
+	override("stringer-connector", "connector between field name and value in String()",
+		func(cfg *Config, v string) { cfg.Stringer.Connector = v })
+
+	boolOverride := func(name, usage string, apply func(cfg *Config, value bool)) {
+		fs.BoolFunc(name, usage, func(value string) error {
+			v, err := strconv.ParseBool(value)
+			if err != nil {
+				return err
+			}
+			opts.overrides[name] = func(cfg *Config) { apply(cfg, v) }
+			return nil
+		})
+	}
+
+	boolOverride("json", "generate MarshalJSON for wrappers",
+		func(cfg *Config, v bool) { cfg.Generator.JSON = v })
+	boolOverride("location", "record file:line of the return site in wrappers",
+		func(cfg *Config, v bool) { cfg.Generator.Location = v })
+	boolOverride("opt-in", "process only functions with //errgen:wrap, go:generate files and generator.packages",
+		func(cfg *Config, v bool) { cfg.Generator.OptIn = v })
+	boolOverride("slog", "generate slog.LogValuer for wrappers",
+		func(cfg *Config, v bool) { cfg.Generator.Slog = v })
+	boolOverride("stack", "capture the call stack in wrapper constructors",
+		func(cfg *Config, v bool) { cfg.Generator.Stack = v })
+	boolOverride("with-default", "use default skipper preset",
+		func(cfg *Config, v bool) { cfg.Skipper.WithDefault = v })
+
+	fs.Func("skip", "skip files by rule <type>:<value>, e.g. suffix:_gen.go (repeatable)", func(value string) error {
+		typ, val, ok := strings.Cut(value, ":")
+		if !ok {
+			return errors.New("expected <type>:<value>")
+		}
+		prev := opts.overrides["skip"]
+		opts.overrides["skip"] = func(cfg *Config) {
+			if prev != nil {
+				prev(cfg)
+			}
+			cfg.Skipper.Rules = append(cfg.Skipper.Rules, skipper.Rule{Type: skipper.RuleType(typ), Value: val})
+		}
+		return nil
+	})
+
+	return fs
+}
+
+// load returns config for parsed flags with applied overrides.
+func (o *options) load(fs *flag.FlagSet) (*Config, error) {
+	dir, err := filepath.Abs(o.dir)
+	if err != nil {
+		return nil, err
+	}
+	o.dir = dir
+
+	o.patterns = fs.Args()
+	if err := o.goGenerate(fs); err != nil {
+		return nil, err
+	}
+
+	if len(o.patterns) == 0 {
+		o.patterns = []string{"./..."}
+	}
+
+	cfg, err := loadConfig(o.configPath, o.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, apply := range o.overrides {
+		apply(cfg)
+	}
+
+	// Templates are looked up relative to the sources root
+	for _, path := range []*string{&cfg.Generator.WrapperTemplate, &cfg.Generator.SentinelTemplate, &cfg.Stringer.Template} {
+		if *path != "" && !filepath.IsAbs(*path) {
+			*path = filepath.Join(o.dir, *path)
+		}
+	}
+
+	return cfg, nil
+}
+
+// goGenerate adjusts options when errgen is run by "//go:generate errgen"
+// without --dir and packages: only the package of $GOFILE is processed, the module
+// root is the sources root, so names and locations are the same as for the whole
+// tree, and the nearest config between the package and the root is used.
+func (o *options) goGenerate(fs *flag.FlagSet) error {
+	if os.Getenv("GOFILE") == "" || os.Getenv("GOPACKAGE") == "" || len(o.patterns) > 0 {
+		return nil
+	}
+
+	dirSet := false
+	fs.Visit(func(f *flag.Flag) { dirSet = dirSet || f.Name == "dir" })
+	if dirSet {
+		return nil
+	}
+
+	pkgDir := o.dir
+	root := moduleRoot(pkgDir)
+	rel, err := filepath.Rel(root, pkgDir)
+	if err != nil {
+		return err
+	}
+
+	o.dir = root
+	o.patterns = []string{"./" + filepath.ToSlash(rel)}
+	if o.configPath == "" {
+		o.configPath = findConfig(pkgDir, root)
+	}
+
+	return nil
+}
+
+// moduleRoot returns the nearest directory with go.mod, dir itself
+// is returned when there is no module.
+func moduleRoot(dir string) string {
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(filepath.Join(d, "go.mod")); err == nil {
+			return d
+		}
+
+		if filepath.Dir(d) == d {
+			return dir
+		}
+	}
+}
+
+// findConfig returns the nearest config from dir up to root,
+// empty path is returned when there is no config.
+func findConfig(dir, root string) string {
+	for d := dir; ; d = filepath.Dir(d) {
+		path := filepath.Join(d, defaultConfigName)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+
+		if d == root || filepath.Dir(d) == d {
+			return ""
+		}
+	}
+}
+
+// loadConfig reads config from path. If path is empty, the default config
+// in dir is used and, when it does not exist, built-in defaults are returned.
+func loadConfig(path, dir string) (*Config, error) {
+	cfg := &Config{}
+
+	explicit := path != ""
+	if !explicit {
+		path = filepath.Join(dir, defaultConfigName)
+	}
+
+	_, err := os.Stat(path)
+	switch {
+	case err == nil:
+		if err := cleanenv.ReadConfig(path, cfg); err != nil {
+			return nil, fmt.Errorf("read config %s: %w", path, err)
+		}
+	case errors.Is(err, os.ErrNotExist) && !explicit:
+		if err := cleanenv.ReadEnv(cfg); err != nil {
+			return nil, fmt.Errorf("read default config: %w", err)
+		}
+	default:
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// isPattern reports whether the first argument is the package pattern
+// and not the command: "./...", "internal/...", "/abs/path", "pkg/user"
+// or the existing directory "internal".
+func isPattern(arg string) bool {
+	if strings.HasPrefix(arg, ".") || strings.Contains(arg, "/") || strings.Contains(arg, "...") {
+		return true
+	}
+
+	info, err := os.Stat(arg)
+	return err == nil && info.IsDir()
+}
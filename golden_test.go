@@ -0,0 +1,143 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Bionic2113/errgen/internal/vfs"
+)
+
+var update = flag.Bool("update", false, "write regenerated files into testdata/example")
+
+const exampleDir = "testdata/example"
+
+// TestGolden regenerates the example from its sources before errgen
+// (*.go.before) and checks that every file is byte-identical to the committed
+// one. The generation runs twice in separate copies, so unstable output is
+// caught even when it happens to match once, and errgen check must pass on
+// the result.
+func TestGolden(t *testing.T) {
+	first := generateExample(t)
+	second := generateExample(t)
+
+	generated := goFiles(t, first)
+	for name, data := range generated {
+		if other := readFile(t, filepath.Join(second, name)); data != other {
+			t.Errorf("%s differs between runs:\n%s", name, vfs.Unified("first/"+name, "second/"+name, data, other))
+		}
+	}
+
+	if *update {
+		for name, data := range generated {
+			if err := os.WriteFile(filepath.Join(exampleDir, name), []byte(data), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return
+	}
+
+	committed := goFiles(t, exampleDir)
+	for name, data := range generated {
+		want, ok := committed[name]
+		switch {
+		case !ok:
+			t.Errorf("%s is generated, but not committed", name)
+		case want != data:
+			t.Errorf("%s is stale, run go test -run TestGolden . -update:\n%s",
+				name, vfs.Unified("committed/"+name, "generated/"+name, want, data))
+		}
+	}
+
+	for name := range committed {
+		if _, ok := generated[name]; !ok {
+			t.Errorf("%s is committed, but not generated", name)
+		}
+	}
+
+	if code := run([]string{"check", "--dir", first}); code != 0 {
+		t.Errorf("errgen check fails on the generated example with code %d", code)
+	}
+}
+
+// generateExample copies the example sources before errgen
+// into the temporary directory and runs errgen there.
+func generateExample(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	entries, err := os.ReadDir(exampleDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || isGenerated(name) {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(exampleDir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		switch {
+		case strings.HasSuffix(name, ".go.before"):
+			name = strings.TrimSuffix(name, ".before")
+		case strings.HasSuffix(name, ".go") && fileExists(filepath.Join(exampleDir, name+".before")):
+			// The file after errgen is restored from its source
+			continue
+		}
+
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if code := run([]string{"generate", "--dir", dir}); code != 0 {
+		t.Fatalf("errgen generate exited with code %d", code)
+	}
+
+	return dir
+}
+
+// isGenerated reports whether the example file is written by errgen only.
+func isGenerated(name string) bool {
+	return strings.HasSuffix(name, "_gen.go") || name == "strings.go"
+}
+
+// goFiles returns contents of Go files in dir by their names.
+func goFiles(t *testing.T, dir string) map[string]string {
+	t.Helper()
+
+	matches, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := make(map[string]string, len(matches))
+	for _, path := range matches {
+		files[filepath.Base(path)] = readFile(t, path)
+	}
+
+	return files
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(data)
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -1,77 +1,114 @@
 package collector
 
 import (
-	"bytes"
-	"go/parser"
-	"go/printer"
+	_ "embed"
+	"errors"
+	"fmt"
 	"go/token"
-	"html/template"
-	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"text/template"
 
 	"github.com/Bionic2113/errgen/internal/generator"
+	"github.com/Bionic2113/errgen/internal/report"
 	"github.com/Bionic2113/errgen/pkg/utils"
 	"github.com/dave/dst"
-	"github.com/dave/dst/decorator"
 )
 
-const tmplt = `// Code generated by errgen. DO NOT EDIT.
-package {{.Package}}
-import "errors"
+//go:embed templates/sentinel.go.tmpl
+var defaultTemplate string
 
-var (
-	{{range $text, $name := .Errors}}
-	  {{$name}} = errors.New("{{$text}}") {{end}}
-	)
-`
+// SentinelData is the data of the sentinel template.
+type SentinelData struct {
+	// Package is the package name.
+	Package string
+	// Sentinels are the errors of the package sorted by name.
+	Sentinels []Sentinel
+}
+
+// HasFormat reports whether some sentinel is created with fmt.Errorf.
+func (d SentinelData) HasFormat() bool {
+	for _, s := range d.Sentinels {
+		if s.Format {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Sentinel is the error created with errors.New or fmt.Errorf.
+type Sentinel struct {
+	// Name is the variable name, e.g. ErrUserIsNil.
+	Name string
+	// Text is the message as it is written in the string literal.
+	Text string
+	// Format reports whether the sentinel is created with fmt.Errorf.
+	Format bool
+}
 
 // При записи в файл проверяем, что file != nil,
 // Иначе нужно не обновлять, а создавать новый
 type ErrorInfo struct {
-	existsErrors map[string]string
+	// existsErrors are names of messages, new returns with the message use them.
+	existsErrors map[generator.Message]string
+	// texts are messages of all sentinels by names, pinned names
+	// may have the same message as other sentinels.
+	texts map[string]generator.Message
+}
+
+func newErrorInfo() *ErrorInfo {
+	return &ErrorInfo{
+		existsErrors: make(map[generator.Message]string),
+		texts:        make(map[string]generator.Message),
+	}
+}
+
+func (ei *ErrorInfo) add(name string, msg generator.Message) {
+	if _, ok := ei.existsErrors[msg]; !ok {
+		ei.existsErrors[msg] = name
+	}
+	ei.texts[name] = msg
 }
 
 type ErrorCollector struct {
 	errorInfos map[utils.PkgInfo]*ErrorInfo
 	filename   string
+	tmpl       *template.Template
+	cfg        generator.SentinelConfig
 }
 
-func New(filename string) (*ErrorCollector, error) {
-	currentDir, err := os.Getwd()
-	if err != nil {
-		return nil, err
-	}
+// LoadTemplate parses the sentinel template from path,
+// the built-in one is used when path is empty.
+func LoadTemplate(path string) (*template.Template, error) {
+	return utils.LoadTemplate("sentinel", path, defaultTemplate, nil)
+}
 
-	ec := &ErrorCollector{
+// New creates collector, existing sentinels are loaded with ProcessFile,
+// so only processed packages are generated.
+func New(filename string, tmpl *template.Template, cfg generator.SentinelConfig) *ErrorCollector {
+	return &ErrorCollector{
 		errorInfos: make(map[utils.PkgInfo]*ErrorInfo),
 		filename:   filename,
+		tmpl:       tmpl,
+		cfg:        cfg,
 	}
-	if err := ec.ProcessFiles(currentDir); err != nil {
-		return nil, err
-	}
-
-	return ec, nil
 }
 
-func (ec *ErrorCollector) ProcessFiles(dir string) error {
-	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		// Нас интересуют только наши сгенерированные ошибки
-		if !strings.HasSuffix(path, ec.filename+".go") {
-			return nil
-		}
+func (ec *ErrorCollector) info(pkgInfo utils.PkgInfo) *ErrorInfo {
+	einfo := ec.errorInfos[pkgInfo]
+	if einfo == nil {
+		einfo = newErrorInfo()
+		ec.errorInfos[pkgInfo] = einfo
+	}
 
-		return ec.ProcessFile(dir, path)
-	})
+	return einfo
 }
 
 func (ec *ErrorCollector) ProcessFile(dir, path string) error {
-	node, err := decorator.ParseFile(token.NewFileSet(), path, nil, parser.ParseComments)
+	node, _, err := utils.ParseFile(token.NewFileSet(), path, nil)
 	if err != nil {
 		return err
 	}
@@ -97,105 +134,110 @@ func (ec *ErrorCollector) CollectErrors(node *dst.File, pkgInfo utils.PkgInfo, c
 				continue
 			}
 
-			text, ok, _ := generator.ExtractErrorMessage(val.Values[0])
+			msg, ok := generator.ConstantMessage(val.Values[0])
 			if !ok {
 				continue
 			}
 
-			einfo := ec.errorInfos[pkgInfo]
-			if einfo == nil {
-				einfo = &ErrorInfo{
-					existsErrors: make(map[string]string),
-				}
-				ec.errorInfos[pkgInfo] = einfo
-			}
-
-			einfo.existsErrors[text] = val.Names[0].Name
+			ec.info(pkgInfo).add(val.Names[0].Name, msg)
 		}
 
 		return true
 	})
 }
 
-func (ec *ErrorCollector) ErrorName(pkgInfo utils.PkgInfo, errText string) string {
-	einfo := ec.errorInfos[pkgInfo]
-	if einfo == nil {
-		einfo = &ErrorInfo{
-			existsErrors: make(map[string]string),
+// ErrorName returns the sentinel name for the message. Existing sentinels keep
+// their names, new ones are named by the config. Pinned name is used instead
+// when it is not empty, names declared in the package are skipped.
+func (ec *ErrorCollector) ErrorName(pkgInfo utils.PkgInfo, msg generator.Message, pinned string, resolver utils.TypeResolver) string {
+	einfo := ec.info(pkgInfo)
+	if pinned != "" {
+		if existing, ok := einfo.texts[pinned]; !ok || existing == msg {
+			einfo.add(pinned, msg)
+			return pinned
 		}
-		ec.errorInfos[pkgInfo] = einfo
-
-		firstErr := generateErrorName(pkgInfo, "1")
-		einfo.existsErrors[errText] = firstErr
-
-		return firstErr
 	}
 
-	name, ok := einfo.existsErrors[errText]
-	if ok {
+	if name, ok := einfo.existsErrors[msg]; ok {
 		return name
 	}
 
-	nextErr := generateErrorName(pkgInfo, strconv.Itoa(len(einfo.existsErrors)+1))
-	einfo.existsErrors[errText] = nextErr
+	var name string
+	if base := messageName(msg.Text, ec.cfg); base != "" && ec.cfg.Naming == generator.SentinelMessage {
+		// Collisions get numeric suffixes: ErrNotFound, ErrNotFound2
+		name = base
+		for i := 2; einfo.taken(name, resolver); i++ {
+			name = base + strconv.Itoa(i)
+		}
+	} else {
+		for n := len(einfo.texts) + 1; name == "" || einfo.taken(name, resolver); n++ {
+			name = ec.numberedName(pkgInfo, n)
+		}
+	}
+	einfo.add(name, msg)
 
-	return nextErr
+	return name
 }
 
-func generateErrorName(pkgInfo utils.PkgInfo, suffix string) string {
-	return "Err" + strings.ToUpper(string(pkgInfo.Name[0])) + pkgInfo.Name[1:] + suffix
+// SentinelMessage returns the message of the sentinel by its name.
+func (ec *ErrorCollector) SentinelMessage(pkgInfo utils.PkgInfo, name string) (generator.Message, bool) {
+	msg, ok := ec.info(pkgInfo).texts[name]
+	return msg, ok
 }
 
-func (ec *ErrorCollector) GenerateFiles() error {
-	for pkgInfo, einfo := range ec.errorInfos {
-		if err := ec.generateFile(pkgInfo, einfo); err != nil {
-			return err
-		}
+// taken reports whether the name is used by other sentinel or declared in the package.
+func (ei *ErrorInfo) taken(name string, resolver utils.TypeResolver) bool {
+	if _, ok := ei.texts[name]; ok {
+		return true
 	}
 
-	return nil
+	return resolver != nil && resolver.Package() != nil && resolver.Package().Scope().Lookup(name) != nil
 }
 
-func (ec *ErrorCollector) generateFile(pkgInfo utils.PkgInfo, einfo *ErrorInfo) error {
-	data := struct {
-		Package string
-		Errors  map[string]string
-	}{Package: pkgInfo.Name, Errors: einfo.existsErrors}
-
-	errFilePath := filepath.Join(pkgInfo.Path, ec.filename+".go")
+func (ec *ErrorCollector) numberedName(pkgInfo utils.PkgInfo, n int) string {
+	return ec.cfg.Prefix + strings.ToUpper(string(pkgInfo.Name[0])) + pkgInfo.Name[1:] + strconv.Itoa(n)
+}
 
-	f, err := os.Create(errFilePath)
-	if err != nil {
-		return err
+func (ec *ErrorCollector) GenerateFiles(fs utils.FileSystem) error {
+	var errs []error
+	for _, pkgInfo := range utils.SortedPackages(ec.errorInfos) {
+		if err := ec.generateFile(fs, pkgInfo, ec.errorInfos[pkgInfo]); err != nil {
+			errs = append(errs, report.File(report.Generate, filepath.Join(pkgInfo.Path, ec.filename+".go"), err))
+		}
 	}
-	defer f.Close()
 
-	t, err := template.New("error_gen").Parse(tmplt)
-	if err != nil {
-		return err
-	}
+	return errors.Join(errs...)
+}
 
-	formattedBuf := &bytes.Buffer{}
-	if err := t.Execute(formattedBuf, data); err != nil {
-		return err
+func (ec *ErrorCollector) generateFile(fs utils.FileSystem, pkgInfo utils.PkgInfo, einfo *ErrorInfo) error {
+	data := SentinelData{Package: pkgInfo.Name}
+	for name, msg := range einfo.texts {
+		data.Sentinels = append(data.Sentinels, Sentinel{Name: name, Text: msg.Text, Format: msg.Format})
 	}
+	sort.Slice(data.Sentinels, func(i, j int) bool { return data.Sentinels[i].Name < data.Sentinels[j].Name })
 
-	cfg := printer.Config{Mode: printer.UseSpaces | printer.TabIndent, Tabwidth: 8}
-
-	fset := token.NewFileSet()
-	astFile, err := parser.ParseFile(fset, "", formattedBuf.String(), parser.ParseComments)
+	node, src, err := utils.RenderGo(ec.tmpl, data)
 	if err != nil {
 		return err
 	}
 
-	var buf bytes.Buffer
-	if err := cfg.Fprint(&buf, fset, astFile); err != nil {
-		return err
+	// Sources refer to sentinels by names, so all of them must be declared
+	declared := make(map[string]struct{})
+	for _, decl := range node.Decls {
+		if gen, ok := decl.(*dst.GenDecl); ok && gen.Tok == token.VAR {
+			for _, spec := range gen.Specs {
+				for _, name := range spec.(*dst.ValueSpec).Names {
+					declared[name.Name] = struct{}{}
+				}
+			}
+		}
 	}
 
-	if err := os.WriteFile(errFilePath, buf.Bytes(), 0o644); err != nil {
-		return err
+	for _, s := range data.Sentinels {
+		if _, ok := declared[s.Name]; !ok {
+			return fmt.Errorf("sentinel template: variable %s is not declared", s.Name)
+		}
 	}
 
-	return nil
+	return fs.WriteFile(filepath.Join(pkgInfo.Path, ec.filename+".go"), src)
 }
@@ -0,0 +1,58 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/Bionic2113/errgen/internal/generator"
+	"github.com/Bionic2113/errgen/pkg/utils"
+	"github.com/dave/dst/decorator"
+)
+
+func TestErrorName(t *testing.T) {
+	cfg := generator.SentinelConfig{Naming: generator.SentinelMessage, Prefix: "Err", Case: generator.SentinelGoCase}
+	pkg := utils.PkgInfo{Name: "user", Path: "/src/user"}
+	ec := New("error_gen", nil, cfg)
+
+	node, err := decorator.Parse(`package user
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrMissing = errors.New("not found")
+	ErrFull    = fmt.Errorf("100%% full")
+)
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ec.CollectErrors(node, pkg, "/src")
+
+	notFound := generator.Message{Text: "not found"}
+	tests := []struct {
+		name   string
+		msg    generator.Message
+		pinned string
+		want   string
+	}{
+		{"existing sentinel keeps its name", notFound, "", "ErrMissing"},
+		{"existing formatted sentinel", generator.Message{Text: "100%% full", Format: true}, "", "ErrFull"},
+		{"new sentinel", generator.Message{Text: "user is nil"}, "", "ErrUserIsNil"},
+		{"same message is reused", generator.Message{Text: "user is nil"}, "", "ErrUserIsNil"},
+		{"fmt.Errorf gets its own sentinel", generator.Message{Text: "user is nil", Format: true}, "", "ErrUserIsNil2"},
+		{"pinned name", notFound, "ErrUserNotFound", "ErrUserNotFound"},
+		{"pinned name of other message", generator.Message{Text: "gone"}, "ErrMissing", "ErrGone"},
+	}
+
+	for _, tt := range tests {
+		if got := ec.ErrorName(pkg, tt.msg, tt.pinned, nil); got != tt.want {
+			t.Errorf("%s: ErrorName(%+v, %q) = %q, want %q", tt.name, tt.msg, tt.pinned, got, tt.want)
+		}
+	}
+
+	if msg, ok := ec.SentinelMessage(pkg, "ErrFull"); !ok || !msg.Format {
+		t.Errorf("SentinelMessage(ErrFull) = %+v, %v, want the fmt.Errorf message", msg, ok)
+	}
+}
@@ -0,0 +1,55 @@
+package collector
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/Bionic2113/errgen/internal/generator"
+)
+
+// initialisms are written in upper case with the go case, like golint suggests.
+var initialisms = map[string]bool{
+	"ACL": true, "API": true, "ASCII": true, "CPU": true, "CSS": true, "DB": true,
+	"DNS": true, "EOF": true, "GUID": true, "HTML": true, "HTTP": true, "HTTPS": true,
+	"ID": true, "IP": true, "JSON": true, "JWT": true, "LHS": true, "QPS": true,
+	"RAM": true, "RHS": true, "RPC": true, "SLA": true, "SMTP": true, "SQL": true,
+	"SSH": true, "TCP": true, "TLS": true, "TTL": true, "UDP": true, "UI": true,
+	"UID": true, "URI": true, "URL": true, "UTF8": true, "UUID": true, "VM": true,
+	"XML": true, "XMPP": true, "XSRF": true, "XSS": true,
+}
+
+// messageName returns the sentinel name made of the message words:
+// "user is nil" becomes ErrUserIsNil. Empty string is returned
+// when the message has no letters.
+func messageName(text string, cfg generator.SentinelConfig) string {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return r > unicode.MaxASCII || (!unicode.IsLetter(r) && !unicode.IsDigit(r))
+	})
+	if cfg.MaxWords > 0 && len(words) > cfg.MaxWords {
+		words = words[:cfg.MaxWords]
+	}
+
+	var b strings.Builder
+	b.WriteString(cfg.Prefix)
+	hasLetters := false
+	for _, word := range words {
+		hasLetters = hasLetters || strings.IndexFunc(word, unicode.IsLetter) >= 0
+
+		upper := strings.ToUpper(word)
+		switch {
+		case cfg.Case == generator.SentinelGoCase && initialisms[upper]:
+			b.WriteString(upper)
+		case cfg.Case == generator.SentinelGoCase && word != upper:
+			// Keep camel case words like userID
+			b.WriteString(upper[:1] + word[1:])
+		default:
+			b.WriteString(upper[:1] + strings.ToLower(word[1:]))
+		}
+	}
+
+	if !hasLetters {
+		return ""
+	}
+
+	return b.String()
+}
@@ -0,0 +1,35 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/Bionic2113/errgen/internal/generator"
+)
+
+func TestMessageName(t *testing.T) {
+	goCase := generator.SentinelConfig{Prefix: "Err", Case: generator.SentinelGoCase, MaxWords: 6}
+	titleCase := generator.SentinelConfig{Prefix: "Err", Case: generator.SentinelTitleCase}
+	unexported := generator.SentinelConfig{Prefix: "err", Case: generator.SentinelGoCase, MaxWords: 2}
+
+	tests := []struct {
+		text string
+		cfg  generator.SentinelConfig
+		want string
+	}{
+		{"user is nil", goCase, "ErrUserIsNil"},
+		{"user id is nil", goCase, "ErrUserIDIsNil"},
+		{"user id is nil", titleCase, "ErrUserIdIsNil"},
+		{"userID is empty", goCase, "ErrUserIDIsEmpty"},
+		{"count is over 100%%", goCase, "ErrCountIsOver100"},
+		{"one two three four five six seven", goCase, "ErrOneTwoThreeFourFiveSix"},
+		{"not found: item", unexported, "errNotFound"},
+		{"пользователь не найден", goCase, ""},
+		{"404", goCase, ""},
+	}
+
+	for _, tt := range tests {
+		if got := messageName(tt.text, tt.cfg); got != tt.want {
+			t.Errorf("messageName(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
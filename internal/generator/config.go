@@ -0,0 +1,142 @@
+package generator
+
+import (
+	"fmt"
+	"go/token"
+
+	"github.com/Bionic2113/errgen/pkg/utils"
+)
+
+// Config controls generated wrappers.
+type Config struct {
+	// Naming is the text/template of wrapper type names, see NameData.
+	// Constructors are named New<wrapper type name>.
+	Naming string `yaml:"naming" env-default:"{{.Name}}Error"`
+	// IsStrategy defines which targets are matched by errors.Is, see IsStrategy constants.
+	IsStrategy IsStrategy `yaml:"is_strategy" env-default:"reason"`
+	// WrapperTemplate and SentinelTemplate are paths to text/template files
+	// used instead of the built-in templates, see WrapperData
+	// and collector.SentinelData.
+	WrapperTemplate  string `yaml:"wrapper_template"`
+	SentinelTemplate string `yaml:"sentinel_template"`
+	// Location adds file:line of the return site to wrappers. It is written
+	// into sources as the constructor argument, so it costs nothing at runtime.
+	Location bool `yaml:"location"`
+	// Stack captures the call stack in constructors, it is printed with %+v.
+	Stack bool `yaml:"stack"`
+	// Slog generates LogValue, so wrappers are logged by log/slog as groups.
+	Slog bool `yaml:"slog"`
+	// JSON generates MarshalJSON, so wrappers are encoded as documents
+	// with their context and the cause.
+	JSON bool `yaml:"json"`
+	// OptIn processes only functions with the wrap directive, files with
+	// "//go:generate errgen" and Packages. Other functions are left untouched.
+	OptIn bool `yaml:"opt_in"`
+	// Packages are processed entirely in the opt-in mode. They are patterns
+	// relative to the sources root like the command arguments: "./internal/...".
+	Packages []string `yaml:"packages"`
+	// Sentinel configures names of generated sentinel errors.
+	Sentinel SentinelConfig `yaml:"sentinel"`
+}
+
+// SentinelConfig configures names of sentinel errors. Names of existing
+// sentinels are never changed, so they are safe to use with errors.Is.
+type SentinelConfig struct {
+	// Naming is message (ErrUserIsNil from "user is nil")
+	// or numbered (ErrUser1, ErrUser2 in the order of discovery).
+	Naming SentinelNaming `yaml:"naming" env-default:"message"`
+	// Prefix of names, "err" makes sentinels unexported.
+	Prefix string `yaml:"prefix" env-default:"Err"`
+	// Case of message words: go keeps initialisms upper case (ErrUserIDIsNil),
+	// title capitalises only the first letter (ErrUserIdIsNil).
+	Case SentinelCase `yaml:"case" env-default:"go"`
+	// MaxWords limits the number of message words in names, 0 means no limit.
+	MaxWords int `yaml:"max_words" env-default:"6"`
+}
+
+type SentinelNaming string
+
+const (
+	SentinelMessage  SentinelNaming = "message"
+	SentinelNumbered SentinelNaming = "numbered"
+)
+
+type SentinelCase string
+
+const (
+	SentinelGoCase    SentinelCase = "go"
+	SentinelTitleCase SentinelCase = "title"
+)
+
+// constructorArity returns the number of constructor parameters of the function wrapper.
+func (c Config) constructorArity(f utils.FunctionInfo) int {
+	n := len(f.Args) + 2
+	if f.Values {
+		n++
+	}
+	if c.Location {
+		n++
+	}
+
+	return n
+}
+
+func (c Config) constructorParamNames(f utils.FunctionInfo) string {
+	names := "reason and error"
+	if c.Location {
+		names = "location, " + names
+	}
+	if f.Values {
+		names = "values, " + names
+	}
+
+	return names
+}
+
+// IsStrategy defines the Is method of wrappers. Causes of the wrapper
+// are always matched by errors.Is through Unwrap.
+type IsStrategy string
+
+const (
+	// IsType matches any wrapper of the same function.
+	IsType IsStrategy = "type"
+	// IsReason matches wrappers of the same function with the same reason.
+	IsReason IsStrategy = "reason"
+	// IsPointer matches only the wrapper itself, Is method is not generated.
+	IsPointer IsStrategy = "pointer"
+	// IsSentinel matches wrappers of the same function with the same cause,
+	// so returns with different sentinel errors can be told apart.
+	IsSentinel IsStrategy = "sentinel"
+)
+
+// Validate checks values which can not be checked by yaml decoding.
+func (c Config) Validate() error {
+	switch c.IsStrategy {
+	case IsType, IsReason, IsPointer, IsSentinel:
+	default:
+		return fmt.Errorf("unknown is_strategy %q, expected one of: %s, %s, %s, %s",
+			c.IsStrategy, IsType, IsReason, IsPointer, IsSentinel)
+	}
+
+	switch c.Sentinel.Naming {
+	case SentinelMessage, SentinelNumbered:
+	default:
+		return fmt.Errorf("unknown sentinel.naming %q, expected %s or %s", c.Sentinel.Naming, SentinelMessage, SentinelNumbered)
+	}
+
+	switch c.Sentinel.Case {
+	case SentinelGoCase, SentinelTitleCase:
+	default:
+		return fmt.Errorf("unknown sentinel.case %q, expected %s or %s", c.Sentinel.Case, SentinelGoCase, SentinelTitleCase)
+	}
+
+	if !token.IsIdentifier(c.Sentinel.Prefix) {
+		return fmt.Errorf("sentinel.prefix %q is not a valid identifier", c.Sentinel.Prefix)
+	}
+
+	if c.Sentinel.MaxWords < 0 {
+		return fmt.Errorf("sentinel.max_words %d is negative", c.Sentinel.MaxWords)
+	}
+
+	return nil
+}
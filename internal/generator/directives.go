@@ -0,0 +1,177 @@
+package generator
+
+import (
+	"go/token"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/Bionic2113/errgen/pkg/utils"
+	"github.com/dave/dst"
+	"github.com/dave/dst/dstutil"
+)
+
+// Source directives tune the output without the config:
+//
+//	//errgen:file-ignore          before the package clause, the file is not processed
+//	//errgen:ignore               in the function doc, the function is not wrapped
+//	//errgen:skip-arg ctx,db      in the function doc, arguments are not kept in the wrapper
+//	//errgen:reason "loading user" at the return, the reason of the wrapper
+//	//errgen:wrap                 in the function doc, the function is processed in the opt-in mode
+//	//errgen:sentinel ErrNotFound at the return, the name of the generated sentinel
+const (
+	fileIgnoreDirective = "file-ignore"
+	ignoreDirective     = "ignore"
+	skipArgDirective    = "skip-arg"
+	reasonDirective     = "reason"
+	wrapDirective       = "wrap"
+	sentinelDirective   = "sentinel"
+)
+
+const goGenerateDirective = "//go:generate "
+
+// IsFileIgnored reports whether the file has the file-ignore directive.
+func IsFileIgnored(node *dst.File) bool {
+	for _, decs := range [][]string{node.Decs.Start, node.Decs.Package, node.Decs.Name} {
+		if _, ok := utils.Directive(decs, fileIgnoreDirective); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isIgnored(funcDecl *dst.FuncDecl) bool {
+	_, ok := utils.Directive(funcDecl.Decs.Start, ignoreDirective)
+	return ok
+}
+
+func isWrapMarked(funcDecl *dst.FuncDecl) bool {
+	_, ok := utils.Directive(funcDecl.Decs.Start, wrapDirective)
+	return ok
+}
+
+// OptedIn reports whether the file is processed in the opt-in mode:
+// its package is listed in generator.packages (optedIn) or the file
+// runs errgen with go:generate. All files are processed without opt-in.
+func OptedIn(node *dst.File, cfg Config, optedIn bool) bool {
+	return optedIn || !cfg.OptIn || HasGenerateDirective(node)
+}
+
+// HasGenerateDirective reports whether the file runs errgen with go:generate,
+// e.g. "//go:generate errgen" or "//go:generate go run github.com/Bionic2113/errgen@latest".
+func HasGenerateDirective(node *dst.File) bool {
+	decs := [][]string{node.Decs.Start, node.Decs.Package, node.Decs.Name}
+	for _, decl := range node.Decls {
+		decs = append(decs, decl.Decorations().Start, decl.Decorations().End)
+	}
+
+	for _, lines := range decs {
+		for _, line := range lines {
+			command, ok := strings.CutPrefix(line, goGenerateDirective)
+			if !ok {
+				continue
+			}
+
+			for _, field := range strings.Fields(command) {
+				name, _, _ := strings.Cut(path.Base(field), "@")
+				if name == "errgen" {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// skippedArgs returns names from skip-arg directives of the function,
+// names are separated by commas or spaces.
+func skippedArgs(funcDecl *dst.FuncDecl) map[string]bool {
+	names := make(map[string]bool)
+	for _, line := range funcDecl.Decs.Start {
+		value, ok := utils.Directive([]string{line}, skipArgDirective)
+		if !ok {
+			continue
+		}
+
+		for _, name := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' }) {
+			names[name] = true
+		}
+	}
+
+	return names
+}
+
+// directiveReason returns the reason directive of the return statement
+// (or the assignment in the deferred function), it can be placed above
+// the statement or at its end. Quotes are optional: the quoted reason is
+// the Go string literal, otherwise it is taken as is. The reason is returned
+// as the content of the string literal.
+func directiveReason(stmt dst.Stmt) (string, bool) {
+	for _, decs := range [][]string{stmt.Decorations().Start, stmt.Decorations().End} {
+		value, ok := utils.Directive(decs, reasonDirective)
+		if !ok || value == "" {
+			continue
+		}
+
+		if strings.HasPrefix(value, `"`) {
+			if text, err := strconv.Unquote(value); err == nil {
+				value = text
+			}
+		}
+
+		quoted := strconv.Quote(value)
+		return quoted[1 : len(quoted)-1], true
+	}
+
+	return "", false
+}
+
+// pinnedSentinel returns the sentinel name from the directive of the return
+// statement, empty string is returned when there is no valid name.
+func pinnedSentinel(stmt dst.Stmt) string {
+	for _, decs := range [][]string{stmt.Decorations().Start, stmt.Decorations().End} {
+		if value, ok := utils.Directive(decs, sentinelDirective); ok && token.IsIdentifier(value) {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// UnwrapIgnored replaces wrapper constructor calls in ignored functions
+// (or everywhere in the ignored file) with their errors, so the directive
+// can be added to already processed code. It reports whether the file was changed.
+func UnwrapIgnored(node *dst.File, resolver utils.TypeResolver) bool {
+	fileIgnored := IsFileIgnored(node)
+
+	var changed bool
+	for _, decl := range node.Decls {
+		funcDecl, ok := decl.(*dst.FuncDecl)
+		if !ok || funcDecl.Body == nil || (!fileIgnored && !isIgnored(funcDecl)) {
+			continue
+		}
+
+		dstutil.Apply(funcDecl.Body, nil, func(cursor *dstutil.Cursor) bool {
+			call, ok := cursor.Node().(*dst.CallExpr)
+			if !ok || !IsWrapperCall(call, resolver) || len(call.Args) < 2 {
+				return true
+			}
+
+			if lit, ok := call.Args[len(call.Args)-2].(*dst.BasicLit); !ok || lit.Kind != token.STRING {
+				return true
+			}
+
+			errArg := call.Args[len(call.Args)-1]
+			errArg.Decorations().Before = call.Decs.Before
+			errArg.Decorations().After = call.Decs.After
+			cursor.Replace(errArg)
+			changed = true
+
+			return true
+		})
+	}
+
+	return changed
+}
@@ -0,0 +1,117 @@
+package generator
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator"
+)
+
+func TestDirectiveReason(t *testing.T) {
+	tests := []struct {
+		comment string
+		want    string
+	}{
+		{`//errgen:reason loading user`, `loading user`},
+		{`//errgen:reason "loading user"`, `loading user`},
+		{`//errgen:reason "say \"hi\""`, `say \"hi\"`},
+		{`//errgen:reason "C:\\tmp"`, `C:\\tmp`},
+		{`//errgen:reason say "hi"`, `say \"hi\"`},
+		{`//errgen:reason "unterminated`, `\"unterminated`},
+		{`//errgen:reason C:\tmp`, `C:\\tmp`},
+	}
+
+	for _, tt := range tests {
+		stmt := &dst.ReturnStmt{}
+		stmt.Decs.Start.Append(tt.comment)
+
+		got, ok := directiveReason(stmt)
+		if !ok || got != tt.want {
+			t.Errorf("directiveReason(%s) = %s, %v, want %s", tt.comment, got, ok, tt.want)
+		}
+	}
+
+	if _, ok := directiveReason(&dst.ReturnStmt{}); ok {
+		t.Error("directiveReason found the reason without the directive")
+	}
+}
+
+func TestAnalyzeFunctionsDirectives(t *testing.T) {
+	src := `package a
+
+import (
+	"context"
+	"errors"
+)
+
+//errgen:ignore
+func load(ctx context.Context) error { return nil }
+
+//errgen:skip-arg ctx
+func Get(ctx context.Context, id int) error {
+	if err := load(ctx); err != nil {
+		//errgen:reason "loading user"
+		return err
+	}
+	if id == 0 {
+		return errors.New("id is zero") //errgen:sentinel ErrNoID
+	}
+
+	return nil
+}
+
+//errgen:ignore
+func Put(id int) error {
+	return NewPutError(id, "load", errors.New("failed"))
+}
+`
+	out, functions := analyze(t, src, Config{})
+
+	if len(functions) != 1 || len(functions[0].Args) != 1 || functions[0].Args[0].Name != "id" {
+		t.Fatalf("wrappers are %+v, want Get with the id argument", functions)
+	}
+
+	for _, want := range []string{
+		`return NewGetError(id, "loading user", err)`,
+		`return NewGetError(id, "unknown error in Get", ErrNoID) //errgen:sentinel ErrNoID`,
+		`return NewPutError(id, "load", errors.New("failed"))`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output has no %q:\n%s", want, out)
+		}
+	}
+
+	node, err := decorator.Parse(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !UnwrapIgnored(node, nil) {
+		t.Fatal("UnwrapIgnored does not change the ignored function")
+	}
+
+	var buf bytes.Buffer
+	if err := decorator.Fprint(&buf, node); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); !strings.Contains(got, `return errors.New("failed")`) || !strings.Contains(got, "return NewGetError(") {
+		t.Errorf("UnwrapIgnored must unwrap only the ignored function:\n%s", got)
+	}
+}
+
+func TestAnalyzeFunctionsFileIgnore(t *testing.T) {
+	src := `//errgen:file-ignore
+package a
+
+import "errors"
+
+func Get() error {
+	return errors.New("failed")
+}
+`
+	out, functions := analyze(t, src, Config{})
+	if len(functions) != 0 || out != src {
+		t.Errorf("ignored file is processed:\n%s", out)
+	}
+}
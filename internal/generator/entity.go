@@ -2,7 +2,24 @@ package generator
 
 import "github.com/Bionic2113/errgen/pkg/utils"
 
-type ErrorTemplate struct {
-	Package   string
+// WrapperData is the data of the wrapper template.
+type WrapperData struct {
+	// Package is the package name.
+	Package string
+	// Imports are packages which the template may use by their names:
+	// packages of argument types, errors, fmt, strconv
+	// and others used by the built-in template.
+	// Unused imports are removed from the rendered file.
+	Imports map[string]utils.Path
+	// Functions are the wrapped functions of the package.
 	Functions []utils.FunctionInfo
+	// IsStrategy is generator.is_strategy.
+	IsStrategy IsStrategy
+	// Location and Stack are generator.location and generator.stack.
+	Location bool
+	Stack    bool
+	// Slog is generator.slog.
+	Slog bool
+	// JSON is generator.json.
+	JSON bool
 }
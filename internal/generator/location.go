@@ -0,0 +1,61 @@
+package generator
+
+import (
+	"bytes"
+	"go/token"
+	"strconv"
+
+	"github.com/Bionic2113/errgen/pkg/utils"
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator"
+)
+
+// StampLocations sets name:line of the return site into every constructor
+// call of functions in src. Lines are known only after the modified file
+// is printed, because rewriting changes imports.
+func StampLocations(src []byte, name string, functions []utils.FunctionInfo) ([]byte, error) {
+	params := make(map[string]int, len(functions))
+	for _, f := range functions {
+		params["New"+f.TypeName] = Config{Location: true}.constructorArity(f)
+	}
+
+	fset := token.NewFileSet()
+	node, dec, err := utils.ParseFile(fset, name, src)
+	if err != nil {
+		return nil, err
+	}
+
+	dst.Inspect(node, func(n dst.Node) bool {
+		call, ok := n.(*dst.CallExpr)
+		if !ok {
+			return true
+		}
+
+		ident, ok := call.Fun.(*dst.Ident)
+		if !ok {
+			return true
+		}
+
+		if n, ok := params[ident.Name]; !ok || n != len(call.Args) {
+			return true
+		}
+
+		lit, ok := call.Args[len(call.Args)-3].(*dst.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			return true
+		}
+
+		if n, ok := dec.Ast.Nodes[call]; ok {
+			lit.Value = strconv.Quote(name + ":" + strconv.Itoa(fset.Position(n.Pos()).Line))
+		}
+
+		return true
+	})
+
+	var buf bytes.Buffer
+	if err := decorator.Fprint(&buf, node); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,43 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Bionic2113/errgen/pkg/utils"
+)
+
+func TestStampLocations(t *testing.T) {
+	src := `package a
+
+func Get(id int) error {
+	if err := noArgs(); err != nil {
+		return NewGetError(id, "", "get", err)
+	}
+
+	if err := other(id, "", "x", nil); err != nil {
+		return err
+	}
+
+	return NewGetError(id, "", "get", nil)
+}
+`
+	functions := []utils.FunctionInfo{{TypeName: "GetError", Args: []utils.ArgInfo{{Name: "id", Type: "int"}}}}
+
+	out, err := StampLocations([]byte(src), "a.go", functions)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := string(out)
+	for _, want := range []string{
+		`return NewGetError(id, "a.go:5", "get", err)`,
+		`return NewGetError(id, "a.go:12", "get", nil)`,
+		`if err := noArgs(); err != nil {`,
+		`if err := other(id, "", "x", nil); err != nil {`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("StampLocations output has no %q:\n%s", want, got)
+		}
+	}
+}
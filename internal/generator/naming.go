@@ -0,0 +1,152 @@
+package generator
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Bionic2113/errgen/pkg/utils"
+	"github.com/dave/dst"
+)
+
+// NameData is the data of the naming scheme.
+type NameData struct {
+	// Receiver is the receiver type name without pointer and type parameters,
+	// empty for functions.
+	Receiver string
+	// Function is the function or method name.
+	Function string
+	// Name is Receiver and Function joined in camel case, e.g. UserSave
+	// for (*User).Save and Save for the function Save.
+	Name string
+}
+
+// TypeNamer gives names of wrapper types.
+type TypeNamer interface {
+	TypeName(f utils.FunctionInfo) (string, error)
+}
+
+// Namer gives names of wrappers by the naming scheme.
+type Namer struct {
+	tmpl *template.Template
+}
+
+func NewNamer(scheme string) (*Namer, error) {
+	tmpl, err := template.New("naming").Parse(scheme)
+	if err != nil {
+		return nil, fmt.Errorf("parse naming scheme: %w", err)
+	}
+
+	n := &Namer{tmpl: tmpl}
+
+	// Check the scheme right away instead of failing on every function
+	samples := []utils.FunctionInfo{
+		{FunctionName: "Save"},
+		{FunctionName: "Save", ReceiverType: "User"},
+	}
+	for _, f := range samples {
+		if _, err := n.TypeName(f); err != nil {
+			return nil, err
+		}
+	}
+
+	return n, nil
+}
+
+// TypeName returns the wrapper type name of the function.
+func (n *Namer) TypeName(f utils.FunctionInfo) (string, error) {
+	receiver, _, _ := strings.Cut(f.ReceiverType, "[")
+	data := NameData{Receiver: receiver, Function: f.FunctionName, Name: f.FunctionName}
+	if receiver != "" {
+		data.Name = receiver + upperFirst(f.FunctionName)
+	}
+
+	var buf strings.Builder
+	if err := n.tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("naming scheme: %w", err)
+	}
+
+	name := buf.String()
+	if !token.IsIdentifier(name) {
+		return "", fmt.Errorf("naming scheme gives invalid name %q for %s", name, QualifiedName(f))
+	}
+
+	return name, nil
+}
+
+// QualifiedName returns the function name as it is written in Go docs: User.Save or Save.
+func QualifiedName(f utils.FunctionInfo) string {
+	if f.ReceiverType == "" {
+		return f.FunctionName
+	}
+
+	return f.ReceiverType + "." + f.FunctionName
+}
+
+// DeclaredName returns the name of user declaration which clashes
+// with the wrapper type or its constructor. Previously generated wrappers
+// do not clash, they are replaced by the new ones.
+func DeclaredName(typeName string, resolver utils.TypeResolver) string {
+	if resolver == nil || resolver.Package() == nil {
+		return ""
+	}
+
+	scope := resolver.Package().Scope()
+	if obj := scope.Lookup(typeName); obj != nil && !isWrapperType(obj.Type()) {
+		return obj.Name()
+	}
+
+	if obj := scope.Lookup("New" + typeName); obj != nil && !isWrapperConstructor(obj) {
+		return obj.Name()
+	}
+
+	return ""
+}
+
+func upperFirst(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[size:]
+}
+
+// isWrapperType reports whether t is the generated wrapper struct.
+func isWrapperType(t types.Type) bool {
+	st, ok := t.Underlying().(*types.Struct)
+	if !ok {
+		return false
+	}
+
+	for i := range st.NumFields() {
+		if st.Field(i).Name() == errFieldName {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WrapperTypes returns names of the wrapper structs declared in the file.
+func WrapperTypes(node *dst.File) map[string]struct{} {
+	wrappers := make(map[string]struct{})
+	dst.Inspect(node, func(n dst.Node) bool {
+		spec, ok := n.(*dst.TypeSpec)
+		if !ok {
+			return true
+		}
+
+		if st, ok := spec.Type.(*dst.StructType); ok {
+			for _, field := range st.Fields.List {
+				if len(field.Names) > 0 && field.Names[0].Name == errFieldName {
+					wrappers[spec.Name.Name] = struct{}{}
+				}
+			}
+		}
+
+		return false
+	})
+
+	return wrappers
+}
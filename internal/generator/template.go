@@ -0,0 +1,179 @@
+package generator
+
+import (
+	_ "embed"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"text/template"
+
+	"github.com/Bionic2113/errgen/pkg/utils"
+	"github.com/dave/dst"
+)
+
+//go:embed templates/wrapper.go.tmpl
+var defaultWrapperTemplate string
+
+// templateFuncs are available in the wrapper template.
+var templateFuncs = template.FuncMap{
+	"isFunc":       isFuncType,
+	"hasRedaction": hasRedaction,
+	"hasValues":    hasValues,
+}
+
+// hasRedaction reports whether any argument of functions is rendered with mode.
+func hasRedaction(functions []utils.FunctionInfo, mode utils.Redaction) bool {
+	for _, f := range functions {
+		for _, arg := range f.Args {
+			if arg.Redact == mode {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// hasValues reports whether any wrapper of functions keeps values of fmt.Errorf arguments.
+func hasValues(functions []utils.FunctionInfo) bool {
+	for _, f := range functions {
+		if f.Values {
+			return true
+		}
+	}
+
+	return false
+}
+
+// LoadWrapperTemplate parses the wrapper template from path,
+// the built-in one is used when path is empty.
+func LoadWrapperTemplate(path string) (*template.Template, error) {
+	return utils.LoadTemplate("wrapper", path, defaultWrapperTemplate, templateFuncs)
+}
+
+func GenerateErrorFile(
+	fs utils.FileSystem,
+	filename string,
+	pkgInfo utils.PkgInfo,
+	functions []utils.FunctionInfo,
+	cfg Config,
+	tmpl *template.Template,
+) error {
+	imports := map[string]utils.Path{
+		"errors":  {Path: "errors"},
+		"json":    {Path: "encoding/json"},
+		"fmt":     {Path: "fmt"},
+		"hex":     {Path: "encoding/hex"},
+		"io":      {Path: "io"},
+		"slog":    {Path: "log/slog"},
+		"runtime": {Path: "runtime"},
+		"sha256":  {Path: "crypto/sha256"},
+		"sort":    {Path: "sort"},
+		"strconv": {Path: "strconv"},
+	}
+	functions = addArgImports(imports, functions)
+
+	data := WrapperData{
+		Package:    pkgInfo.Name,
+		Imports:    imports,
+		Functions:  functions,
+		IsStrategy: cfg.IsStrategy,
+		Location:   cfg.Location,
+		Stack:      cfg.Stack,
+		Slog:       cfg.Slog,
+		JSON:       cfg.JSON,
+	}
+
+	node, src, err := utils.RenderGo(tmpl, data)
+	if err != nil {
+		return err
+	}
+
+	if err := checkWrappers(node, functions, cfg); err != nil {
+		return fmt.Errorf("wrapper template: %w", err)
+	}
+
+	return fs.WriteFile(filepath.Join(pkgInfo.Path, filename+".go"), src)
+}
+
+// addArgImports adds packages of argument types to imports. Packages which
+// clash by name with other imports, e.g. github.com/pkg/errors with errors
+// of the template, are imported with aliases, so functions are returned
+// with argument types which use them.
+func addArgImports(imports map[string]utils.Path, functions []utils.FunctionInfo) []utils.FunctionInfo {
+	aliases := make(map[string]string)
+	result := make([]utils.FunctionInfo, len(functions))
+	for i, f := range functions {
+		f.Args = slices.Clone(f.Args)
+		for j, arg := range f.Args {
+			for _, name := range slices.Sorted(maps.Keys(arg.Imports)) {
+				path := arg.Imports[name]
+				if imp, ok := imports[name]; !ok || imp.Path == path.Path {
+					imports[name] = path
+					continue
+				}
+
+				alias, ok := aliases[path.Path]
+				if !ok {
+					alias = uniqueName(name, imports)
+					aliases[path.Path] = alias
+					imports[alias] = utils.Path{Alias: alias, Path: path.Path}
+				}
+				arg.Type = renameQualifier(arg.Type, name, alias)
+			}
+			f.Args[j] = arg
+		}
+		result[i] = f
+	}
+
+	return result
+}
+
+// uniqueName returns name with the smallest numeric suffix which is not in imports.
+func uniqueName(name string, imports map[string]utils.Path) string {
+	for i := 2; ; i++ {
+		alias := name + strconv.Itoa(i)
+		if _, ok := imports[alias]; !ok {
+			return alias
+		}
+	}
+}
+
+// renameQualifier replaces the package name in qualified identifiers of the type.
+func renameQualifier(typ, name, alias string) string {
+	re := regexp.MustCompile(`(^|[^\w.])` + regexp.QuoteMeta(name) + `\.`)
+	return re.ReplaceAllString(typ, "${1}"+alias+".")
+}
+
+// checkWrappers checks that the rendered file has everything which
+// rewritten sources rely on: wrapper structs with the error field
+// and their constructors.
+func checkWrappers(node *dst.File, functions []utils.FunctionInfo, cfg Config) error {
+	wrappers := WrapperTypes(node)
+	constructors := make(map[string]int)
+	for _, decl := range node.Decls {
+		if funcDecl, ok := decl.(*dst.FuncDecl); ok && funcDecl.Recv == nil {
+			constructors[funcDecl.Name.Name] = funcDecl.Type.Params.NumFields()
+		}
+	}
+
+	for _, f := range functions {
+		if _, ok := wrappers[f.TypeName]; !ok {
+			return fmt.Errorf("%s must be a struct with %s field", f.TypeName, errFieldName)
+		}
+
+		params, ok := constructors["New"+f.TypeName]
+		if !ok {
+			return fmt.Errorf("constructor New%s is not declared", f.TypeName)
+		}
+
+		if params != cfg.constructorArity(f) {
+			return fmt.Errorf("constructor New%s must have arguments, %s parameters", f.TypeName, cfg.constructorParamNames(f))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,184 @@
+package generator
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Bionic2113/errgen/internal/vfs"
+	"github.com/Bionic2113/errgen/pkg/utils"
+)
+
+// generate renders wrappers of functions by the built-in template.
+func generate(t *testing.T, cfg Config, functions ...utils.FunctionInfo) string {
+	t.Helper()
+
+	tmpl, err := LoadWrapperTemplate("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fs := vfs.NewOverlay()
+	pkg := utils.PkgInfo{Name: "a", Path: t.TempDir()}
+	if err := GenerateErrorFile(fs, "errwrap_gen", pkg, functions, cfg, tmpl); err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := fs.ReadFile(pkg.Path + "/errwrap_gen.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(src)
+}
+
+func TestGenerateErrorFileImportClash(t *testing.T) {
+	pkgErrors := map[string]utils.Path{"errors": {Path: "github.com/pkg/errors"}}
+	functions := []utils.FunctionInfo{
+		{
+			FunctionName: "Get", TypeName: "GetError",
+			Args: []utils.ArgInfo{
+				{Name: "frame", Type: "errors.Frame", Imports: pkgErrors},
+				{Name: "frames", Type: "map[string][]*errors.Frame", Imports: pkgErrors},
+			},
+		},
+		{
+			FunctionName: "Put", TypeName: "PutError",
+			Args: []utils.ArgInfo{
+				{Name: "n", Type: "json.Number", Imports: map[string]utils.Path{"json": {Path: "encoding/json"}}},
+				{Name: "v", Type: "json.Value", Imports: map[string]utils.Path{"json": {Path: "example.com/json"}}},
+			},
+		},
+	}
+
+	src := generate(t, Config{IsStrategy: IsSentinel, JSON: true}, functions...)
+	for _, want := range []string{
+		`errors2 "github.com/pkg/errors"`,
+		`json2 "example.com/json"`,
+		"\t\"errors\"\n",
+		"\t\"encoding/json\"\n",
+		"frame        errors2.Frame",
+		"frames       map[string][]*errors2.Frame",
+		"n            json.Number",
+		"v            json2.Value",
+		"errors.Is(e.errErrGen, t.errErrGen)",
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("generated file has no %q:\n%s", want, src)
+		}
+	}
+
+	// Arguments of the processor are not changed
+	if functions[0].Args[0].Type != "errors.Frame" {
+		t.Errorf("argument type is changed to %q", functions[0].Args[0].Type)
+	}
+}
+
+func TestGenerateErrorFileAllOptions(t *testing.T) {
+	functions := []utils.FunctionInfo{
+		{
+			PackageName: "a", PackagePath: "example.com/a", FunctionName: "Save", ReceiverType: "Repo", TypeName: "RepoSaveError",
+			Values: true,
+			Args: []utils.ArgInfo{
+				{Name: "name", Type: "string"},
+				{Name: "id", Type: "int"},
+				{Name: "timeout", Type: "time.Duration", Imports: map[string]utils.Path{"time": {Path: "time"}}},
+				{Name: "token", Type: "string", Redact: utils.RedactHash},
+				{Name: "password", Type: "string", Redact: utils.RedactMask},
+				{Name: "user", Type: "any", Redact: utils.RedactFields},
+				{Name: "fn", Type: "func()"},
+			},
+		},
+		{PackageName: "a", PackagePath: "example.com/a", FunctionName: "Load", TypeName: "LoadError"},
+	}
+
+	for _, strategy := range []IsStrategy{IsType, IsReason, IsPointer, IsSentinel} {
+		cfg := Config{IsStrategy: strategy, Location: true, Stack: true, Slog: true, JSON: true}
+		src := generate(t, cfg, functions...)
+
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, "errwrap_gen.go", src, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+		if _, err := conf.Check("example.com/a", fset, []*ast.File{file}, nil); err != nil {
+			t.Errorf("%s: generated file does not compile: %v\n%s", strategy, err, src)
+		}
+	}
+}
+
+func TestGenerateErrorFileCustomTemplate(t *testing.T) {
+	functions := []utils.FunctionInfo{{FunctionName: "Get", TypeName: "GetError", Args: []utils.ArgInfo{{Name: "id", Type: "int"}}}}
+
+	tests := []struct {
+		name string
+		text string
+		err  string
+	}{
+		{
+			"valid",
+			`package {{.Package}}
+{{range .Functions}}
+type {{.TypeName}} struct{ errErrGen error }
+
+func New{{.TypeName}}({{range .Args}}{{.Name}} {{.Type}}, {{end}}reason string, err error) *{{.TypeName}} {
+	return &{{.TypeName}}{errErrGen: err}
+}
+{{end}}`,
+			"",
+		},
+		{
+			"without the error field",
+			`package {{.Package}}
+{{range .Functions}}
+type {{.TypeName}} struct{ err error }
+
+func New{{.TypeName}}(id int, reason string, err error) *{{.TypeName}} { return nil }
+{{end}}`,
+			"GetError must be a struct with errErrGen field",
+		},
+		{
+			"without arguments",
+			`package {{.Package}}
+{{range .Functions}}
+type {{.TypeName}} struct{ errErrGen error }
+
+func New{{.TypeName}}(reason string, err error) *{{.TypeName}} { return nil }
+{{end}}`,
+			"constructor NewGetError must have arguments, reason and error parameters",
+		},
+		{
+			"invalid Go",
+			`package {{.Package}} func`,
+			"template output is not valid Go",
+		},
+	}
+
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "wrapper.go.tmpl")
+		if err := os.WriteFile(path, []byte(tt.text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		tmpl, err := LoadWrapperTemplate(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = GenerateErrorFile(vfs.NewOverlay(), "errwrap_gen", utils.PkgInfo{Name: "a", Path: t.TempDir()}, functions, Config{}, tmpl)
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("%s: %v", tt.name, err)
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("%s: error is %v, want %q", tt.name, err, tt.err)
+		}
+	}
+}
@@ -1,117 +1,138 @@
 package generator
 
 import (
-	"bytes"
-	"fmt"
-	"go/parser"
-	"go/printer"
+	"errors"
 	"go/token"
-	"html/template"
-	"os"
-	"path/filepath"
+	"go/types"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/Bionic2113/errgen/pkg/utils"
 	"github.com/dave/dst"
 )
 
-const tmplt = `// Code generated by errgen. DO NOT EDIT.
-	package {{.Package}}
-
-import (
-	{{range $k, $val := .Imports}} {{$val.Alias}} "{{$val.Path}}"
-{{end}})
-{{range .Functions}}
-
-type {{.FunctionName}}Error struct {
-	{{- range .Args}}
-	{{.Name}} {{.Type}}
-	{{- end}}
-	%[1]s string
-	%[2]s    error
-}
-
-func New{{.FunctionName}}Error({{range .Args}}{{.Name}} {{.Type}}, {{end}}%[1]s string, %[2]s error) *{{.FunctionName}}Error {
-	return &{{.FunctionName}}Error{
-		{{- range .Args}}
-		{{.Name}}: {{.Name}},
-		{{- end}}
-		%[1]s: %[1]s,
-		%[2]s: %[2]s,
-	}
-}
-
-func (e *{{.FunctionName}}Error) Error() string {
-	return "[" + {{if .SubPackageName}}"{{.SubPackageName}}/" +{{end}}"{{.PackageName}}" + {{if .ReceiverType}}".{{.ReceiverType}}" +{{end}}"] - " +
-		"{{.FunctionName}} - " + e.%[1]s +
-		{{if .Args}}" - args: {" + {{/* start range */}}{{range $i, $arg := .Args}}{{if $i}} + ", " +{{end}}
-		"{{.Name}}: " + {{if eq .Type "string"}}e.{{.Name}}{{else if eq .Type "int"}}strconv.Itoa(e.{{.Name}}){{else if eq .Type "int64"}}strconv.FormatInt(e.{{.Name}}, 10){{else if eq .Type "uint64"}}strconv.FormatUint(e.{{.Name}}, 10){{else if eq .Type "float64"}}strconv.FormatFloat(e.{{.Name}}, 'f', -1, 64){{else if eq .Type "bool"}}strconv.FormatBool(e.{{.Name}}){{else}}fmt.Sprintf("%[3]s", e.{{.Name}}){{end}}{{end}} +
-		"}" +{{end}}{{/* end range */}} "\n" +
-		e.%[2]s.Error()
-}
+// Fields of generated wrappers. They are unusual enough
+// to recognise wrappers and not to clash with user fields.
+const (
+	reasonFieldName = "reasonErrGen"
+	errFieldName    = "errErrGen"
+)
 
-func (e *{{.FunctionName}}Error) Unwrap() error {
-	return e.%[2]s
+type ErrorInformator interface {
+	// ErrorName returns the sentinel name for the message, pinned name
+	// is used when it is not empty.
+	ErrorName(pkgInfo utils.PkgInfo, msg Message, pinned string, resolver utils.TypeResolver) string
+	// SentinelMessage returns the message of the sentinel by its name.
+	SentinelMessage(pkgInfo utils.PkgInfo, name string) (Message, bool)
 }
 
-func (e *{{.FunctionName}}Error) Is(target error) bool {
-	if _, ok := target.(*{{.FunctionName}}Error); ok {
-		return true
-	}
-	return errors.Is(e.%[2]s, target)
+// Message is the constant message of the sentinel.
+type Message struct {
+	// Text is the message as it is written in the string literal.
+	Text string
+	// Format reports whether the error is created with fmt.Errorf,
+	// so "%%" of Text is the percent sign.
+	Format bool
 }
-{{end}}`
 
-type ErrorInformator interface {
-	ErrorName(pkgInfo utils.PkgInfo, errText string) string
+// Rewrite is the return statement (or the assignment in the deferred function)
+// which was wrapped by the constructor.
+type Rewrite struct {
+	Stmt        dst.Stmt
+	Constructor string
+	// Stale reports whether the statement was wrapped before by the constructor
+	// of the old name or arguments, and the call was migrated.
+	Stale bool
 }
 
+// AnalyzeFunctions wraps error returns of the file functions and returns
+// their wrappers. In the opt-in mode only functions with the wrap directive
+// are processed unless optedIn is true for the whole file.
 func AnalyzeFunctions(
 	node *dst.File,
 	pkgInfo utils.PkgInfo,
-	subPkg, currentDir, fileName string,
+	subPkg string,
 	errInformator ErrorInformator,
 	skipper utils.Skipper,
-) []utils.FunctionInfo {
-	var functions []utils.FunctionInfo
+	resolver utils.TypeResolver,
+	namer TypeNamer,
+	cfg Config,
+	optedIn bool,
+) ([]utils.FunctionInfo, []Rewrite, error) {
+	var (
+		functions []utils.FunctionInfo
+		rewrites  []Rewrite
+		errs      []error
+	)
 	imports := utils.CollectImports(node)
-	originalPath := filepath.Join(currentDir, subPkg, fileName)
+	if IsFileIgnored(node) {
+		return nil, nil, nil
+	}
+	optedIn = OptedIn(node, cfg, optedIn)
 
 	dst.Inspect(node, func(n dst.Node) bool {
-		if funcDecl, ok := n.(*dst.FuncDecl); ok && HasErrorReturn(funcDecl) {
-			f := utils.CreateFunctionInfo(funcDecl, pkgInfo, subPkg, imports, skipper)
-			functions = append(functions, f)
-			ModifyFunctionBody(funcDecl, f, pkgInfo, errInformator)
+		decl, ok := n.(*dst.FuncDecl)
+		if !ok || isIgnored(decl) {
+			return true
+		}
+
+		// Wrappers of functions which were processed before opting in
+		// are still generated, but the functions are not changed
+		process := optedIn || isWrapMarked(decl)
+		skipped := skippedArgs(decl)
+		for _, funcDecl := range append([]*dst.FuncDecl{decl}, Closures(decl, resolver)...) {
+			// Functions declared without body are implemented in assembly
+			if funcDecl.Body == nil || !HasErrorReturn(funcDecl, resolver) || (!process && !hasWrapperCalls(funcDecl, resolver)) {
+				continue
+			}
+
+			f := utils.CreateFunctionInfo(funcDecl, pkgInfo, subPkg, imports, skipper, resolver)
+			if len(skipped) > 0 {
+				f.Args = slices.DeleteFunc(f.Args, func(arg utils.ArgInfo) bool { return skipped[arg.Name] })
+			}
+
+			typeName, err := namer.TypeName(f)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			f.TypeName = typeName
+			f.Values = hasFormatValues(funcDecl, resolver)
 
+			functions = append(functions, f)
+			if process {
+				rewrites = append(rewrites, ModifyFunctionBody(funcDecl, f, pkgInfo, errInformator, resolver, cfg)...)
+			}
 		}
 		return true
 	})
 
 	if len(functions) > 0 {
 		utils.RemoveUnusedImports(node)
-		utils.WriteModifiedFile(node, originalPath)
 	}
 
-	return functions
+	return functions, rewrites, errors.Join(errs...)
 }
 
-func HasErrorReturn(funcDecl *dst.FuncDecl) bool {
-	if funcDecl.Type.Results == nil {
-		return false
-	}
+func HasErrorReturn(funcDecl *dst.FuncDecl, resolver utils.TypeResolver) bool {
+	return ErrorReturnIndex(funcDecl, resolver) != -1
+}
 
-	for _, result := range funcDecl.Type.Results.List {
-		if ident, ok := result.Type.(*dst.Ident); ok {
-			if ident.Name == "error" {
-				return true
-			}
+// IsErrorResult reports whether the wrapper can be returned as the result.
+// Without types only the error identifier is recognised.
+func IsErrorResult(field *dst.Field, resolver utils.TypeResolver) bool {
+	if resolver != nil {
+		if t := resolver.TypeOf(field.Type); t != nil {
+			return utils.IsErrorType(t)
 		}
 	}
 
-	return false
+	ident, ok := field.Type.(*dst.Ident)
+	return ok && ident.Name == "error"
 }
 
-func ErrorReturnIndex(funcNode dst.Node) int {
+func ErrorReturnIndex(funcNode dst.Node, resolver utils.TypeResolver) int {
 	funcLit := &dst.FuncLit{}
 	switch val := funcNode.(type) {
 	default:
@@ -128,10 +149,8 @@ func ErrorReturnIndex(funcNode dst.Node) int {
 
 	var totalIndex int
 	for _, field := range funcLit.Type.Results.List {
-		if ident, ok := field.Type.(*dst.Ident); ok {
-			if ident.Name == "error" {
-				return totalIndex
-			}
+		if IsErrorResult(field, resolver) {
+			return totalIndex
 		}
 
 		// for someFoo() (arg1, arg2 int, err error)
@@ -145,184 +164,663 @@ func ErrorReturnIndex(funcNode dst.Node) int {
 	return -1
 }
 
-func GenerateErrorFile(filename string, pkgInfo utils.PkgInfo, functions []utils.FunctionInfo) {
-	imports := map[string]utils.Path{"errors": {Path: "errors"}}
-	for _, f := range functions {
-		for _, arg := range f.Args {
+// isFuncType reports whether the type is a function, its value can be printed only as pointer.
+func isFuncType(typeName string) bool {
+	return strings.HasPrefix(typeName, "func(")
+}
 
-			switch {
-			case strings.HasPrefix(arg.Type, "time."):
-				imports["time"] = utils.Path{Path: "time"}
+func ModifyFunctionBody(
+	funcDecl *dst.FuncDecl,
+	info utils.FunctionInfo,
+	pkgInfo utils.PkgInfo,
+	errInformator ErrorInformator,
+	resolver utils.TypeResolver,
+	cfg Config,
+) []Rewrite {
+	if funcDecl.Body == nil {
+		return nil
+	}
 
-			case arg.Type == "int" || arg.Type == "int64" || arg.Type == "uint64":
-				imports["strconv"] = utils.Path{Path: "strconv"}
-			case arg.Type == "float64":
-				imports["strconv"] = utils.Path{Path: "strconv"}
+	parentMap := make(map[dst.Node]dst.Node)
+	dst.Inspect(funcDecl.Body, func(n dst.Node) bool {
+		if n == nil {
+			return false
+		}
 
-			case arg.Type == "bool":
-				imports["strconv"] = utils.Path{Path: "strconv"}
-			case arg.Type == "any" || strings.Contains(arg.Type, "[]"):
-				imports["fmt"] = utils.Path{Path: "fmt"}
-			case !utils.IsBasicType(arg.Type):
-				imports["fmt"] = utils.Path{Path: "fmt"}
+		dst.Inspect(n, func(child dst.Node) bool {
+			if child == nil {
+				return false
+			}
+			if child != n {
+				parentMap[child] = n
 			}
-			if strings.Contains(arg.Type, ".") {
-				parts := strings.SplitN(arg.Type, ".", 2)
-				if len(parts) == 2 {
-					pkgName := strings.TrimPrefix(strings.TrimPrefix(parts[0], "[]"), "*")
-					if importPath, ok := f.Imports[pkgName]; ok {
-						imports[pkgName] = importPath
+
+			return true
+		})
+
+		return true
+	})
+
+	errorIndex := ErrorReturnIndex(funcDecl, resolver)
+	if errorIndex == -1 {
+		return nil
+	}
+
+	m := &bodyModifier{
+		funcDecl:      funcDecl,
+		info:          info,
+		pkgInfo:       pkgInfo,
+		errInformator: errInformator,
+		resolver:      resolver,
+		cfg:           cfg,
+		parentMap:     parentMap,
+	}
+	result := errorResultName(funcDecl, resolver)
+
+	var (
+		rewrites []Rewrite
+		inserts  []func()
+	)
+	dst.Inspect(funcDecl.Body, func(n dst.Node) bool {
+		switch v := n.(type) {
+		case *dst.FuncLit:
+			// Closures have their own wrappers, see Closures
+			return false
+		case *dst.DeferStmt:
+			if lit, ok := v.Call.Fun.(*dst.FuncLit); ok && result != nil {
+				rewrites = append(rewrites, m.modifyDeferred(lit, result)...)
+			}
+			return true
+		case *dst.ReturnStmt:
+			results := v.Results
+			if len(results) == 0 && result != nil && m.inErrorCheck(v, result) {
+				results = resultNames(funcDecl)
+			} else if len(results) == 0 && result != nil {
+				if insert, ok := m.guardNaked(v, result); ok {
+					if insert != nil {
+						inserts = append(inserts, insert)
 					}
+					rewrites = append(rewrites, m.rewrite(v))
 				}
+				return true
+			}
+
+			if errorIndex >= len(results) {
+				return true
+			}
+
+			if wrapped, ok := m.wrap(v, results[errorIndex]); ok {
+				results[errorIndex] = wrapped
+				v.Results = results
+				rewrites = append(rewrites, m.rewrite(v))
 			}
 		}
+
+		return true
+	})
+
+	for _, insert := range inserts {
+		insert()
 	}
 
-	templateData := ErrorTemplate{Package: pkgInfo.Name, Functions: functions}
-	errGenName, reasonErrGen := "errErrGen", "reasonErrGen"
+	return rewrites
+}
 
-	tmpl := fmt.Sprintf(tmplt, reasonErrGen, errGenName, "%#v")
+// bodyModifier wraps errors of the function at return statements
+// and at assignments to its named error result in deferred functions.
+type bodyModifier struct {
+	funcDecl      *dst.FuncDecl
+	info          utils.FunctionInfo
+	pkgInfo       utils.PkgInfo
+	errInformator ErrorInformator
+	resolver      utils.TypeResolver
+	cfg           Config
+	parentMap     map[dst.Node]dst.Node
+	// stale is set by wrap when the wrapper call is migrated.
+	stale bool
+}
 
-	data := struct {
-		Package   string
-		Functions []utils.FunctionInfo
-		Imports   map[string]utils.Path
-	}{Package: templateData.Package, Functions: templateData.Functions, Imports: imports}
+func (m *bodyModifier) constructor() string {
+	return "New" + m.info.TypeName
+}
 
-	errFilePath := filepath.Join(pkgInfo.Path, filename+".go")
+// rewrite returns the rewrite of the wrapped statement.
+func (m *bodyModifier) rewrite(stmt dst.Stmt) Rewrite {
+	r := Rewrite{Stmt: stmt, Constructor: m.constructor(), Stale: m.stale}
+	m.stale = false
 
-	f, err := os.Create(errFilePath)
-	if err != nil {
-		panic(err)
+	return r
+}
+
+// modifyDeferred wraps errors which the deferred function assigns
+// to the named result: defer func() { err = f.Close() }().
+// Errors which may be nil are wrapped under the check:
+//
+//	if err = f.Close(); err != nil {
+//		err = NewWriteError(name, "f.Close", err)
+//	}
+func (m *bodyModifier) modifyDeferred(lit *dst.FuncLit, result *dst.Ident) []Rewrite {
+	var rewrites []Rewrite
+	dst.Inspect(lit.Body, func(n dst.Node) bool {
+		if _, ok := n.(*dst.FuncLit); ok {
+			return false
+		}
+
+		assign, ok := n.(*dst.AssignStmt)
+		if !ok || assign.Tok != token.ASSIGN || len(assign.Lhs) != len(assign.Rhs) {
+			return true
+		}
+
+		for i, lhs := range assign.Lhs {
+			if !m.isResult(lhs, result) || IsNilError(assign.Rhs[i]) || m.isCheckedInit(assign, result) {
+				continue
+			}
+
+			if m.mayBeNil(assign, assign.Rhs[i]) {
+				if m.guard(assign, assign.Rhs[i], result) {
+					rewrites = append(rewrites, m.rewrite(assign))
+				}
+				break
+			}
+
+			if wrapped, ok := m.wrap(assign, assign.Rhs[i]); ok {
+				assign.Rhs[i] = wrapped
+				rewrites = append(rewrites, m.rewrite(assign))
+			}
+		}
+
+		return true
+	})
+
+	return rewrites
+}
+
+// isCheckedInit reports whether the assignment is the init of
+// "if err = f(); err != nil", it is guarded already.
+func (m *bodyModifier) isCheckedInit(assign *dst.AssignStmt, result *dst.Ident) bool {
+	ifStmt, ok := m.parentMap[assign].(*dst.IfStmt)
+	return ok && ifStmt.Init == assign && m.checksResult(ifStmt.Cond, result)
+}
+
+// mayBeNil reports whether the assigned error can be nil: results of calls
+// other than errors.New, fmt.Errorf and wrappers, and variables which are
+// not checked by the enclosing "if cerr != nil".
+func (m *bodyModifier) mayBeNil(site dst.Stmt, expr dst.Expr) bool {
+	switch v := expr.(type) {
+	case *dst.CallExpr:
+		if IsWrapperCall(v, m.resolver) {
+			return false
+		}
+
+		sel, ok := v.Fun.(*dst.SelectorExpr)
+		if !ok {
+			return true
+		}
+		pkg, ok := sel.X.(*dst.Ident)
+		return !ok || !(pkg.Name == "errors" && sel.Sel.Name == "New" || pkg.Name == "fmt" && sel.Sel.Name == "Errorf")
+	case *dst.Ident:
+		var child dst.Node = site
+		for parent := m.parentMap[site]; parent != nil; child, parent = parent, m.parentMap[parent] {
+			if ifStmt, ok := parent.(*dst.IfStmt); ok && child == ifStmt.Body && m.checksResult(ifStmt.Cond, v) {
+				return false
+			}
+			if _, ok := parent.(*dst.FuncLit); ok {
+				break
+			}
+		}
 	}
-	defer f.Close()
 
-	t, err := template.New("errors").Parse(tmpl)
-	if err != nil {
-		panic(err)
+	return true
+}
+
+// guard replaces "err = f()" in its block with
+// "if err = f(); err != nil { err = New...(..., err) }".
+func (m *bodyModifier) guard(assign *dst.AssignStmt, expr dst.Expr, result *dst.Ident) bool {
+	list := m.stmtList(assign)
+	if list == nil {
+		return false
 	}
 
-	formattedBuf := &bytes.Buffer{}
-	if err := t.Execute(formattedBuf, data); err != nil {
-		panic(err)
+	wrapped := m.wrapResult(result, m.guardReason(assign, assign, expr))
+	ifStmt := &dst.IfStmt{
+		Init: assign,
+		Cond: &dst.BinaryExpr{X: dst.NewIdent(result.Name), Op: token.NEQ, Y: dst.NewIdent("nil")},
+		Body: &dst.BlockStmt{List: []dst.Stmt{wrapped}},
 	}
 
-	cfg := printer.Config{Mode: printer.UseSpaces | printer.TabIndent, Tabwidth: 8}
+	// Directives at the end of the line stay with the wrapper
+	ifStmt.Decs.Before, ifStmt.Decs.Start, ifStmt.Decs.After = assign.Decs.Before, assign.Decs.Start, assign.Decs.After
+	wrapped.Decs.End = assign.Decs.End
+	assign.Decs.NodeDecs = dst.NodeDecs{}
+	(*list)[slices.Index(*list, dst.Stmt(assign))] = ifStmt
+	m.parentMap[ifStmt], m.parentMap[assign] = m.parentMap[assign], ifStmt
+
+	return true
+}
+
+// guardNaked wraps the error of the naked return which follows the assignment
+// to the named result: "n, err = r.Read(b); return" gets
+// "if err != nil { err = NewReadError(r, b, "r.Read", err) }" before the return.
+// The check is inserted by the returned function after the body is inspected.
+func (m *bodyModifier) guardNaked(ret *dst.ReturnStmt, result *dst.Ident) (func(), bool) {
+	list := m.stmtList(ret)
+	if list == nil {
+		return nil, false
+	}
 
-	fset := token.NewFileSet()
-	astFile, err := parser.ParseFile(fset, "", formattedBuf.String(), parser.ParseComments)
-	if err != nil {
-		panic(err)
+	index := slices.Index(*list, dst.Stmt(ret))
+	if index < 1 {
+		return nil, false
 	}
 
-	var buf bytes.Buffer
-	if err := cfg.Fprint(&buf, fset, astFile); err != nil {
-		panic(err)
+	switch prev := (*list)[index-1].(type) {
+	case *dst.IfStmt:
+		// The check was inserted before, the wrapper may be stale
+		if prev.Init != nil || prev.Else != nil || !m.checksResult(prev.Cond, result) || len(prev.Body.List) != 1 {
+			return nil, false
+		}
+
+		assign, ok := prev.Body.List[0].(*dst.AssignStmt)
+		if !ok || len(assign.Lhs) != 1 || len(assign.Rhs) != 1 || !m.isResult(assign.Lhs[0], result) {
+			return nil, false
+		}
+
+		if call, ok := assign.Rhs[0].(*dst.CallExpr); !ok || !IsWrapperCall(call, m.resolver) {
+			return nil, false
+		}
+
+		wrapped, ok := m.wrap(assign, assign.Rhs[0])
+		if ok {
+			assign.Rhs[0] = wrapped
+		}
+		return nil, ok
+	case *dst.AssignStmt:
+		if prev.Tok != token.ASSIGN {
+			return nil, false
+		}
+
+		i := slices.IndexFunc(prev.Lhs, func(lhs dst.Expr) bool { return m.isResult(lhs, result) })
+		if i < 0 {
+			return nil, false
+		}
+
+		rhs := prev.Rhs[0]
+		if len(prev.Rhs) == len(prev.Lhs) {
+			rhs = prev.Rhs[i]
+		}
+		if IsNilError(rhs) {
+			return nil, false
+		}
+
+		// Errors which are not nil are wrapped in place
+		if len(prev.Rhs) == len(prev.Lhs) && !m.mayBeNil(prev, rhs) {
+			wrapped, ok := m.wrap(prev, rhs)
+			if ok {
+				prev.Rhs[i] = wrapped
+			}
+			return nil, ok
+		}
+
+		ifStmt := &dst.IfStmt{
+			Cond: &dst.BinaryExpr{X: dst.NewIdent(result.Name), Op: token.NEQ, Y: dst.NewIdent("nil")},
+			Body: &dst.BlockStmt{List: []dst.Stmt{m.wrapResult(result, m.guardReason(ret, prev, rhs))}},
+		}
+
+		return func() {
+			*list = slices.Insert(*list, slices.Index(*list, dst.Stmt(ret)), dst.Stmt(ifStmt))
+		}, true
 	}
 
-	if err := os.WriteFile(errFilePath, buf.Bytes(), 0o644); err != nil {
-		panic(err)
+	return nil, false
+}
+
+// stmtList returns the statement list which contains stmt,
+// nil is returned for statements out of blocks and clauses.
+func (m *bodyModifier) stmtList(stmt dst.Stmt) *[]dst.Stmt {
+	switch parent := m.parentMap[stmt].(type) {
+	case *dst.BlockStmt:
+		return &parent.List
+	case *dst.CaseClause:
+		return &parent.Body
+	case *dst.CommClause:
+		return &parent.Body
 	}
+
+	return nil
 }
 
-func ModifyFunctionBody(
-	funcDecl *dst.FuncDecl,
-	info utils.FunctionInfo,
-	pkgInfo utils.PkgInfo,
-	errInformator ErrorInformator,
-) {
-	parentMap := make(map[dst.Node]dst.Node)
-	dst.Inspect(funcDecl.Body, func(n dst.Node) bool {
-		if n == nil {
-			return false
+// guardReason returns the reason of the error assigned by assign:
+// the reason directive of the site or the assignment, or the called function.
+func (m *bodyModifier) guardReason(site dst.Stmt, assign *dst.AssignStmt, expr dst.Expr) string {
+	reason := "unknown error in " + m.info.FunctionName
+	if directive, ok := directiveReason(site); ok {
+		reason = directive
+	} else if directive, ok := directiveReason(assign); ok {
+		reason = directive
+	} else if call, ok := expr.(*dst.CallExpr); ok {
+		if msg := Reason(call); msg != "" {
+			reason = msg
 		}
+	} else if msg, _, funcLit := FindLastFunctionCall(m.parentMap[assign], m.parentMap); msg != "" && !funcLit {
+		reason = msg
+	}
 
-		dst.Inspect(n, func(child dst.Node) bool {
-			if child == nil {
+	return reason
+}
+
+// wrapResult returns "err = New...(..., err)" for the named result.
+func (m *bodyModifier) wrapResult(result *dst.Ident, reason string) *dst.AssignStmt {
+	return &dst.AssignStmt{
+		Lhs: []dst.Expr{dst.NewIdent(result.Name)},
+		Tok: token.ASSIGN,
+		Rhs: []dst.Expr{&dst.CallExpr{
+			Fun:  dst.NewIdent(m.constructor()),
+			Args: constructorArgs(m.funcDecl, m.info, m.cfg, dst.NewIdent(`"`+reason+`"`), dst.NewIdent(result.Name)),
+		}},
+	}
+}
+
+// isResult reports whether expr is the named error result of the function,
+// not the variable which shadows it.
+func (m *bodyModifier) isResult(expr dst.Expr, result *dst.Ident) bool {
+	ident, ok := expr.(*dst.Ident)
+	if !ok || ident.Name != result.Name {
+		return false
+	}
+
+	if m.resolver != nil {
+		if obj := m.resolver.ObjectOf(ident); obj != nil {
+			return obj == m.resolver.ObjectOf(result)
+		}
+	}
+
+	return true
+}
+
+// inErrorCheck reports whether the naked return is in the body of
+// "if err != nil", so the named result is not nil there and the return
+// can be made explicit. Blocks which set the result to nil are skipped.
+func (m *bodyModifier) inErrorCheck(stmt *dst.ReturnStmt, result *dst.Ident) bool {
+	var child dst.Node = stmt
+	for parent := m.parentMap[stmt]; parent != nil; child, parent = parent, m.parentMap[parent] {
+		ifStmt, ok := parent.(*dst.IfStmt)
+		if !ok || child != ifStmt.Body || !m.checksResult(ifStmt.Cond, result) {
+			continue
+		}
+
+		resets := false
+		dst.Inspect(ifStmt.Body, func(n dst.Node) bool {
+			if _, ok := n.(*dst.FuncLit); ok {
 				return false
 			}
-			if child != n {
-				parentMap[child] = n
+
+			if assign, ok := n.(*dst.AssignStmt); ok && len(assign.Lhs) == len(assign.Rhs) {
+				for i, lhs := range assign.Lhs {
+					resets = resets || (m.isResult(lhs, result) && IsNilError(assign.Rhs[i]))
+				}
 			}
 
-			return true
+			return !resets
 		})
 
-		return true
-	})
+		return !resets
+	}
 
-	errorIndex := ErrorReturnIndex(funcDecl)
-	if errorIndex == -1 {
-		return
+	return false
+}
+
+// checksResult reports whether cond is "err != nil" for the named result,
+// it can be one of the operands of &&.
+func (m *bodyModifier) checksResult(cond dst.Expr, result *dst.Ident) bool {
+	if paren, ok := cond.(*dst.ParenExpr); ok {
+		return m.checksResult(paren.X, result)
 	}
 
-	dst.Inspect(funcDecl.Body, func(n dst.Node) bool {
-		// For anonymous functions
-		// TODO: need refactor
-		errorIndex = func() int {
-			funcLit, ok := n.(*dst.FuncLit)
-			if !ok {
-				return errorIndex
+	binary, ok := cond.(*dst.BinaryExpr)
+	if !ok {
+		return false
+	}
+
+	switch binary.Op {
+	case token.LAND:
+		return m.checksResult(binary.X, result) || m.checksResult(binary.Y, result)
+	case token.NEQ:
+		return (m.isResult(binary.X, result) && IsNilError(binary.Y)) ||
+			(m.isResult(binary.Y, result) && IsNilError(binary.X))
+	}
+
+	return false
+}
+
+// wrap returns the wrapped err of the site, the return statement or the assignment.
+// Already wrapped errors are updated in place, false is returned when the site is kept.
+func (m *bodyModifier) wrap(site dst.Stmt, result dst.Expr) (dst.Expr, bool) {
+	m.stale = false
+	if IsNilError(result) {
+		return nil, false
+	}
+
+	constructor := m.constructor()
+	directive, hasDirective := directiveReason(site)
+	pinned := pinnedSentinel(site)
+	if !IsNeedChange(result, m.resolver) {
+		call, ok := result.(*dst.CallExpr)
+		if !ok {
+			return nil, false
+		}
+
+		// The sentinel was pinned after wrapping
+		if pinned != "" && IsWrapperCall(call, m.resolver) && len(call.Args) > 1 {
+			if ident, ok := call.Args[len(call.Args)-1].(*dst.Ident); ok && ident.Name != pinned {
+				if msg, ok := m.errInformator.SentinelMessage(m.pkgInfo, ident.Name); ok {
+					ident.Name = m.errInformator.ErrorName(m.pkgInfo, msg, pinned, m.resolver)
+				}
 			}
-			return ErrorReturnIndex(funcLit)
-		}()
-		if errorIndex == -1 {
-			return false
 		}
 
-		returnStmt, ok := n.(*dst.ReturnStmt)
-		if !ok || errorIndex >= len(returnStmt.Results) {
-			return true
+		// The reason directive was added or changed after wrapping
+		if hasDirective && IsWrapperCall(call, m.resolver) && len(call.Args) > 1 {
+			if lit, ok := call.Args[len(call.Args)-2].(*dst.BasicLit); ok && lit.Kind == token.STRING && lit.Value != `"`+directive+`"` {
+				lit.Value = `"` + directive + `"`
+			}
 		}
 
-		result := returnStmt.Results[errorIndex]
-		if IsNilError(result) {
-			return true
+		// Wrappers of the renamed function or after the naming scheme
+		// change are migrated, reason and error are kept
+		if isStaleWrapperCall(call, m.funcDecl, m.info, m.resolver, m.cfg) {
+			call.Fun = dst.NewIdent(constructor)
+			call.Args = constructorArgs(m.funcDecl, m.info, m.cfg, call.Args[len(call.Args)-2], call.Args[len(call.Args)-1])
+			m.stale = true
+			return call, true
 		}
 
-		if !IsNeedChange(result) {
-			return true
+		return nil, false
+	}
+
+	// Определяем сообщение об ошибке и нужно ли использовать nil
+	reason := "unknown error in " + m.info.FunctionName
+
+	// Проверяем не создается ли ошибка напрямую
+	msg, ok, useNilError := extractErrorMessage(result, m.resolver)
+	if !ok {
+		// Проверяем, не является ли ошибка результатом вызова функции.
+		// The assignment itself is skipped, it assigns the error
+		var node dst.Node = site
+		if _, ok := site.(*dst.AssignStmt); ok {
+			node = m.parentMap[site]
 		}
 
-		// Определяем сообщение об ошибке и нужно ли использовать nil
-		reason := "unknown error in " + info.FunctionName
+		var funcLit bool
+		msg, _, funcLit = FindLastFunctionCall(node, m.parentMap)
+		if funcLit {
+			if _, ok := site.(*dst.ReturnStmt); ok {
+				return nil, false
+			}
+			msg = ""
+		}
+	}
 
-		// Проверяем не создается ли ошибка напрямую
-		msg, ok, useNilError := ExtractErrorMessage(result)
-		if !ok {
-			// Проверяем, не является ли ошибка результатом вызова функции
-			var funcLit bool
-			msg, ok, funcLit = FindLastFunctionCall(returnStmt, parentMap)
-			if funcLit {
-				return true
+	if msg != "" {
+		reason = msg
+	}
+
+	errArg := IsErrorWrapper(result, m.resolver)
+	if errArg == nil {
+		errArg = result
+		if message, ok := ConstantMessage(result); ok && useNilError {
+			errArg = dst.NewIdent(m.errInformator.ErrorName(m.pkgInfo, message, pinned, m.resolver))
+			reason = "unknown error in " + m.info.FunctionName
+		}
+	}
+
+	if hasDirective {
+		reason = directive
+	}
+
+	return &dst.CallExpr{
+		Fun:  dst.NewIdent(constructor),
+		Args: constructorArgs(m.funcDecl, m.info, m.cfg, dst.NewIdent("\""+reason+"\""), errArg),
+	}, true
+}
+
+// errorResultName returns the name of the error result,
+// nil is returned when results are not named.
+func errorResultName(funcDecl *dst.FuncDecl, resolver utils.TypeResolver) *dst.Ident {
+	if funcDecl.Type.Results == nil {
+		return nil
+	}
+
+	for _, field := range funcDecl.Type.Results.List {
+		if IsErrorResult(field, resolver) {
+			if len(field.Names) == 0 || field.Names[0].Name == "_" {
+				return nil
 			}
+			return field.Names[0]
 		}
+	}
 
-		if msg != "" {
-			reason = msg
+	return nil
+}
+
+// resultNames returns names of all results for the explicit return,
+// nil is returned when some result is not named or is blank.
+func resultNames(funcDecl *dst.FuncDecl) []dst.Expr {
+	if funcDecl.Type.Results == nil {
+		return nil
+	}
+
+	var names []dst.Expr
+	for _, field := range funcDecl.Type.Results.List {
+		if len(field.Names) == 0 {
+			return nil
 		}
 
-		errArg := IsErrorWrapper(result)
-		if errArg == nil {
-			errArg = result
-			if useNilError {
-				errArg = dst.NewIdent(errInformator.ErrorName(pkgInfo, reason))
-				reason = "unknown error in " + info.FunctionName
+		for _, name := range field.Names {
+			if name.Name == "_" {
+				return nil
 			}
+			names = append(names, dst.NewIdent(name.Name))
 		}
+	}
 
-		constructorCall := &dst.CallExpr{
-			Fun: dst.NewIdent("New" + info.FunctionName + "Error"),
-			Args: append(
-				utils.ArgumentNames(funcDecl, info.Args),
-				dst.NewIdent("\""+reason+"\""),
-				errArg,
-			),
+	return names
+}
+
+// Closures returns function literals of the function which return errors
+// as declarations named <Func>Func<N> in the source order, so every literal
+// gets its own wrapper. Bodies are shared, changing them changes the literals.
+func Closures(funcDecl *dst.FuncDecl, resolver utils.TypeResolver) []*dst.FuncDecl {
+	if funcDecl.Body == nil {
+		return nil
+	}
+
+	var closures []*dst.FuncDecl
+	dst.Inspect(funcDecl.Body, func(n dst.Node) bool {
+		lit, ok := n.(*dst.FuncLit)
+		if !ok || ErrorReturnIndex(lit, resolver) == -1 {
+			return true
 		}
-		returnStmt.Results[errorIndex] = constructorCall
+
+		closures = append(closures, &dst.FuncDecl{
+			Recv: funcDecl.Recv,
+			Name: dst.NewIdent(funcDecl.Name.Name + "Func" + strconv.Itoa(len(closures)+1)),
+			// Type parameters of the function are in scope of the literal
+			Type: &dst.FuncType{TypeParams: funcDecl.Type.TypeParams, Params: lit.Type.Params, Results: lit.Type.Results},
+			Body: lit.Body,
+		})
 
 		return true
 	})
+
+	return closures
+}
+
+// constructorArgs returns arguments of the constructor call.
+// Location is empty here, it is set by StampLocations.
+func constructorArgs(funcDecl *dst.FuncDecl, info utils.FunctionInfo, cfg Config, reason, err dst.Expr) []dst.Expr {
+	args := utils.ArgumentNames(funcDecl, info.Args)
+	if info.Values {
+		args = append(args, valuesArg(err, funcDecl))
+	}
+	if cfg.Location {
+		args = append(args, &dst.BasicLit{Kind: token.STRING, Value: `""`})
+	}
+
+	return append(args, reason, err)
+}
+
+// isStaleWrapperCall reports whether call is the wrapper constructor
+// with other name, arguments or values than the function has now.
+func isStaleWrapperCall(call *dst.CallExpr, funcDecl *dst.FuncDecl, info utils.FunctionInfo, resolver utils.TypeResolver, cfg Config) bool {
+	if !IsWrapperCall(call, resolver) || len(call.Args) < 2 {
+		return false
+	}
+
+	// Reason is always the string literal, so other functions
+	// with Error suffix are not touched without types
+	if lit, ok := call.Args[len(call.Args)-2].(*dst.BasicLit); !ok || lit.Kind != token.STRING {
+		return false
+	}
+
+	if call.Fun.(*dst.Ident).Name != "New"+info.TypeName || len(call.Args) != cfg.constructorArity(info) {
+		return true
+	}
+
+	for i, arg := range info.Args {
+		if ident, ok := call.Args[i].(*dst.Ident); !ok || ident.Name != arg.Name {
+			return true
+		}
+	}
+
+	if info.Values {
+		values := valueNames(call.Args[len(info.Args)])
+		return !slices.Equal(values, valueNames(valuesArg(call.Args[len(call.Args)-1], funcDecl)))
+	}
+
+	return false
+}
+
+// hasWrapperCalls reports whether the function returns wrappers already.
+func hasWrapperCalls(funcDecl *dst.FuncDecl, resolver utils.TypeResolver) bool {
+	if funcDecl.Body == nil {
+		return false
+	}
+
+	var found bool
+	dst.Inspect(funcDecl.Body, func(n dst.Node) bool {
+		if _, ok := n.(*dst.FuncLit); ok {
+			return false
+		}
+
+		if call, ok := n.(*dst.CallExpr); ok && IsWrapperCall(call, resolver) && len(call.Args) > 1 {
+			lit, ok := call.Args[len(call.Args)-2].(*dst.BasicLit)
+			found = found || (ok && lit.Kind == token.STRING)
+		}
+
+		return !found
+	})
+
+	return found
 }
 
 func IsNilError(expr dst.Expr) bool {
@@ -335,15 +833,15 @@ func IsNilError(expr dst.Expr) bool {
 
 // Проверяем нужно ли нам заменить возврат ошибки
 // на обертку
-func IsNeedChange(expr dst.Expr) bool {
+func IsNeedChange(expr dst.Expr, resolver utils.TypeResolver) bool {
 	call, ok := expr.(*dst.CallExpr)
 	if !ok {
 		return true
 	}
 
 	// Если не наша обертка, то пропускаем
-	if ident, ok := call.Fun.(*dst.Ident); ok {
-		return !strings.HasSuffix(ident.Name, "Error")
+	if _, ok := call.Fun.(*dst.Ident); ok {
+		return !IsWrapperCall(call, resolver)
 	}
 
 	// Если эти функции - это создание через fmt или errors,
@@ -357,19 +855,51 @@ func IsNeedChange(expr dst.Expr) bool {
 	return false
 }
 
-func IsErrorWrapper(expr dst.Expr) dst.Expr {
-	if callExpr, ok := expr.(*dst.CallExpr); ok {
-		if ident, ok := callExpr.Fun.(*dst.Ident); ok {
-			if strings.HasSuffix(ident.Name, "Error") {
-				return callExpr.Args[len(callExpr.Args)-1]
-			}
+func IsErrorWrapper(expr dst.Expr, resolver utils.TypeResolver) dst.Expr {
+	if callExpr, ok := expr.(*dst.CallExpr); ok && len(callExpr.Args) > 0 {
+		if IsWrapperCall(callExpr, resolver) {
+			return callExpr.Args[len(callExpr.Args)-1]
 		}
 	}
 
 	return nil
 }
 
-func ExtractErrorMessage(expr dst.Expr) (string, bool, bool) {
+// IsWrapperCall reports whether call is the constructor of generated wrapper.
+// With types the constructor must return the wrapper struct,
+// otherwise any function with Error suffix is considered as wrapper.
+func IsWrapperCall(call *dst.CallExpr, resolver utils.TypeResolver) bool {
+	ident, ok := call.Fun.(*dst.Ident)
+	if !ok {
+		return false
+	}
+
+	if resolver != nil {
+		if obj := resolver.ObjectOf(ident); obj != nil {
+			return isWrapperConstructor(obj)
+		}
+	}
+
+	return strings.HasSuffix(ident.Name, "Error")
+}
+
+func isWrapperConstructor(obj types.Object) bool {
+	fn, ok := obj.(*types.Func)
+	if !ok {
+		return false
+	}
+
+	sig := fn.Type().(*types.Signature)
+	if sig.Recv() != nil || sig.Results().Len() != 1 {
+		return false
+	}
+
+	ptr, ok := sig.Results().At(0).Type().(*types.Pointer)
+
+	return ok && isWrapperType(ptr.Elem())
+}
+
+func extractErrorMessage(expr dst.Expr, resolver utils.TypeResolver) (string, bool, bool) {
 	callExpr, ok := expr.(*dst.CallExpr)
 	if !ok {
 		return "", false, false
@@ -380,7 +910,7 @@ func ExtractErrorMessage(expr dst.Expr) (string, bool, bool) {
 		return "", false, false
 	case *dst.Ident:
 		// Если уже была обертка, то забираем причину
-		if strings.HasSuffix(v.Name, "Error") && len(callExpr.Args) > 1 {
+		if IsWrapperCall(callExpr, resolver) && len(callExpr.Args) > 1 {
 			if lit, ok := callExpr.Args[len(callExpr.Args)-2].(*dst.BasicLit); ok {
 				return strings.Trim(lit.Value, `"`), true, false
 			}
@@ -394,22 +924,73 @@ func ExtractErrorMessage(expr dst.Expr) (string, bool, bool) {
 		}
 
 		if (ident.Name == "errors" && v.Sel.Name == "New") || (ident.Name == "fmt" && (v.Sel.Name == "Errorf")) {
-			if len(callExpr.Args) > 0 {
-				if lit, ok := callExpr.Args[0].(*dst.BasicLit); ok {
-					return strings.Trim(lit.Value, `"`), true, true
-				}
-
-				var buf bytes.Buffer
-				printer.Fprint(&buf, token.NewFileSet(), callExpr.Args[0])
-
-				return buf.String(), true, true
+			// Only constant messages become sentinels, other calls are kept
+			// as causes with their values and wrapped errors
+			if msg, ok := ConstantMessage(callExpr); ok {
+				return msg.Text, true, true
 			}
+
+			return "", false, false
 		}
 
 		return ident.Name + "." + v.Sel.Name, true, false
 	}
 }
 
+// ConstantMessage returns the message of errors.New or fmt.Errorf call when it is
+// the string literal without formatting verbs. The text is the content of the
+// interpreted string literal, so raw strings can be used for sentinels.
+func ConstantMessage(expr dst.Expr) (Message, bool) {
+	call, ok := expr.(*dst.CallExpr)
+	if !ok || len(call.Args) != 1 {
+		return Message{}, false
+	}
+
+	sel, ok := call.Fun.(*dst.SelectorExpr)
+	if !ok {
+		return Message{}, false
+	}
+	pkg, ok := sel.X.(*dst.Ident)
+	if !ok {
+		return Message{}, false
+	}
+
+	format := pkg.Name == "fmt" && sel.Sel.Name == "Errorf"
+	if !format && (pkg.Name != "errors" || sel.Sel.Name != "New") {
+		return Message{}, false
+	}
+
+	lit, ok := call.Args[0].(*dst.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return Message{}, false
+	}
+
+	text, err := strconv.Unquote(lit.Value)
+	if err != nil || (format && hasVerbs(text)) {
+		return Message{}, false
+	}
+
+	quoted := strconv.Quote(text)
+	return Message{Text: quoted[1 : len(quoted)-1], Format: format}, true
+}
+
+// hasVerbs reports whether the format has verbs, "%%" is the percent sign.
+func hasVerbs(format string) bool {
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		if i+1 < len(format) && format[i+1] == '%' {
+			i++
+			continue
+		}
+
+		return true
+	}
+
+	return false
+}
+
 func FindLastFunctionCall(node dst.Node, parentMap map[dst.Node]dst.Node) (string, bool, bool) {
 	parent := parentMap[node]
 	for parent != nil {
@@ -470,7 +1051,6 @@ func FindLastFunctionCall(node dst.Node, parentMap map[dst.Node]dst.Node) (strin
 func Reason(expr dst.Expr) string {
 	switch v := expr.(type) {
 	default:
-		fmt.Printf("reason default: %#v\n", v)
 		return ""
 	case *dst.CallExpr:
 		return Reason(v.Fun)
@@ -0,0 +1,171 @@
+package generator
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/Bionic2113/errgen/pkg/utils"
+	"github.com/dave/dst/decorator"
+)
+
+// sentinels names sentinel errors by their messages: "user is nil" is ErrUserIsNil.
+type sentinels map[string]Message
+
+func (s sentinels) ErrorName(_ utils.PkgInfo, msg Message, pinned string, _ utils.TypeResolver) string {
+	name := pinned
+	if name == "" {
+		name = "Err"
+		for _, word := range strings.Fields(msg.Text) {
+			name += upperFirst(word)
+		}
+	}
+	s[name] = msg
+
+	return name
+}
+
+func (s sentinels) SentinelMessage(_ utils.PkgInfo, name string) (Message, bool) {
+	msg, ok := s[name]
+	return msg, ok
+}
+
+// noSkipper keeps all arguments as they are.
+type noSkipper struct{}
+
+func (noSkipper) NeedSkipField(string, string) bool         { return false }
+func (noSkipper) ModuleName(string) string                  { return "" }
+func (noSkipper) NeedRedactArg(string, string, string) bool { return false }
+func (noSkipper) Redaction() utils.Redaction                { return utils.RedactMask }
+func (noSkipper) RedactTag() string                         { return "" }
+
+// analyze runs AnalyzeFunctions on src without types
+// and returns the modified source with wrappers of its functions.
+func analyze(t *testing.T, src string, cfg Config) (string, []utils.FunctionInfo) {
+	t.Helper()
+
+	node, err := decorator.Parse(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Naming == "" {
+		cfg.Naming = "{{.Name}}Error"
+	}
+	namer, err := NewNamer(cfg.Naming)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pkg := utils.PkgInfo{Name: node.Name.Name, Path: "/src/" + node.Name.Name}
+	functions, _, err := AnalyzeFunctions(node, pkg, "", sentinels{}, noSkipper{}, nil, namer, cfg, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := decorator.Fprint(&buf, node); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.String(), functions
+}
+
+func TestAnalyzeFunctionsWithoutBody(t *testing.T) {
+	src := `package a
+
+import "errors"
+
+// asm is implemented in asm_amd64.s.
+func asm(x int) error
+
+func Get(id int) error {
+	return errors.New("not found")
+}
+`
+	out, functions := analyze(t, src, Config{})
+
+	if len(functions) != 1 || functions[0].FunctionName != "Get" {
+		t.Fatalf("wrappers are generated for %+v, want only Get", functions)
+	}
+
+	for _, want := range []string{
+		"func asm(x int) error\n",
+		`return NewGetError(id, "unknown error in Get", ErrNotFound)`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output has no %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestAnalyzeFunctionsDeferred(t *testing.T) {
+	src := `package a
+
+import "os"
+
+func Write(name string) (err error) {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	defer func() { err = f.Close() }()
+
+	_, err = f.WriteString("data")
+	return err
+}
+
+func Nop() (err error) {
+	defer func() { err = nil }()
+	return nil
+}
+`
+	out, _ := analyze(t, src, Config{})
+
+	for _, want := range []string{
+		"\tdefer func() {\n\t\tif err = f.Close(); err != nil {\n\t\t\terr = NewWriteError(name, \"f.Close\", err)\n\t\t}\n\t}()\n",
+		"defer func() { err = nil }()",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output has no %q:\n%s", want, out)
+		}
+	}
+
+	if again, _ := analyze(t, out, Config{}); again != out {
+		t.Errorf("second run changes the output:\n%s", again)
+	}
+}
+
+func TestAnalyzeFunctionsNaked(t *testing.T) {
+	src := `package a
+
+import "io"
+
+func Read(r io.Reader, b []byte) (n int, err error) {
+	n, err = r.Read(b)
+	return
+}
+
+func ReadAll(r io.Reader, b []byte) (n int, err error) {
+	if n, err = r.Read(b); err != nil {
+		return
+	}
+
+	return n, nil
+}
+`
+	out, _ := analyze(t, src, Config{})
+
+	for _, want := range []string{
+		"\tn, err = r.Read(b)\n\tif err != nil {\n\t\terr = NewReadError(r, b, \"r.Read\", err)\n\t}\n\treturn\n",
+		"\tif n, err = r.Read(b); err != nil {\n\t\treturn n, NewReadAllError(r, b, \"r.Read\", err)\n\t}\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output has no %q:\n%s", want, out)
+		}
+	}
+
+	if again, _ := analyze(t, out, Config{}); again != out {
+		t.Errorf("second run changes the output:\n%s", again)
+	}
+}
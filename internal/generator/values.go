@@ -0,0 +1,201 @@
+package generator
+
+import (
+	"go/token"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Bionic2113/errgen/pkg/utils"
+	"github.com/dave/dst"
+)
+
+// valuesFieldName is the wrapper field with values of fmt.Errorf arguments.
+const valuesFieldName = "valuesErrGen"
+
+// hasFormatValues reports whether any error return of the function
+// is fmt.Errorf with values to keep in the wrapper, see formatValues.
+// Returns which are wrapped already are checked by their causes.
+func hasFormatValues(funcDecl *dst.FuncDecl, resolver utils.TypeResolver) bool {
+	errorIndex := ErrorReturnIndex(funcDecl, resolver)
+	if errorIndex == -1 {
+		return false
+	}
+
+	var found bool
+	dst.Inspect(funcDecl.Body, func(n dst.Node) bool {
+		switch v := n.(type) {
+		case *dst.FuncLit:
+			return false
+		case *dst.ReturnStmt:
+			if errorIndex >= len(v.Results) {
+				return true
+			}
+
+			result := v.Results[errorIndex]
+			if cause := IsErrorWrapper(result, resolver); cause != nil {
+				result = cause
+			}
+			found = found || formatValues(result, funcDecl) != nil
+		}
+
+		return !found
+	})
+
+	return found
+}
+
+// formatValues returns the map literal with values of fmt.Errorf arguments
+// by their expressions: map[string]any{"table": table, "u.Name": u.Name}.
+// Only variables and their fields are kept. Wrapped errors are the cause
+// and parameters of the function are its wrapper arguments, so they are
+// not kept twice. Nil is returned when there are no such values.
+func formatValues(expr dst.Expr, funcDecl *dst.FuncDecl) *dst.CompositeLit {
+	call, ok := expr.(*dst.CallExpr)
+	if !ok || len(call.Args) < 2 || call.Ellipsis {
+		return nil
+	}
+
+	sel, ok := call.Fun.(*dst.SelectorExpr)
+	if !ok || sel.Sel.Name != "Errorf" {
+		return nil
+	}
+	if pkg, ok := sel.X.(*dst.Ident); !ok || pkg.Name != "fmt" {
+		return nil
+	}
+
+	lit, ok := call.Args[0].(*dst.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return nil
+	}
+	format, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return nil
+	}
+
+	verbs, ok := formatVerbs(format)
+	if !ok || len(verbs) != len(call.Args)-1 {
+		return nil
+	}
+
+	params := paramNames(funcDecl)
+	seen := make(map[string]bool)
+	values := &dst.CompositeLit{
+		Type: &dst.MapType{Key: dst.NewIdent("string"), Value: dst.NewIdent("any")},
+	}
+	for i, arg := range call.Args[1:] {
+		name, root := valueName(arg)
+		if verbs[i] == 'w' || name == "" || params[root] || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		values.Elts = append(values.Elts, &dst.KeyValueExpr{
+			Key:   &dst.BasicLit{Kind: token.STRING, Value: strconv.Quote(name)},
+			Value: dst.Clone(arg).(dst.Expr),
+		})
+	}
+
+	if len(values.Elts) == 0 {
+		return nil
+	}
+
+	return values
+}
+
+// formatVerbs returns verbs of the format in the order of their arguments.
+// False is returned for formats with argument indexes and * width,
+// their arguments can not be matched by order.
+func formatVerbs(format string) ([]rune, bool) {
+	var verbs []rune
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+
+		i++
+		for i < len(format) && strings.IndexByte("+-# 0123456789.", format[i]) != -1 {
+			i++
+		}
+		if i == len(format) {
+			return verbs, true
+		}
+
+		switch format[i] {
+		case '%':
+			continue
+		case '[', '*':
+			return nil, false
+		}
+
+		verb, size := utf8.DecodeRuneInString(format[i:])
+		verbs = append(verbs, verb)
+		i += size - 1
+	}
+
+	return verbs, true
+}
+
+// valueName returns the name of the variable or its field: "u.Name",
+// and the name of the variable. Empty name is returned for other expressions.
+func valueName(expr dst.Expr) (string, string) {
+	switch v := expr.(type) {
+	case *dst.Ident:
+		if v.Name == "_" || v.Name == "nil" || v.Name == "true" || v.Name == "false" {
+			return "", ""
+		}
+		return v.Name, v.Name
+	case *dst.SelectorExpr:
+		name, root := valueName(v.X)
+		if name == "" {
+			return "", ""
+		}
+		return name + "." + v.Sel.Name, root
+	}
+
+	return "", ""
+}
+
+// paramNames returns names of the function parameters.
+func paramNames(funcDecl *dst.FuncDecl) map[string]bool {
+	names := make(map[string]bool)
+	for _, field := range funcDecl.Type.Params.List {
+		for _, name := range field.Names {
+			names[name.Name] = true
+		}
+	}
+
+	return names
+}
+
+// valuesArg returns the values argument of the constructor call for the cause.
+func valuesArg(cause dst.Expr, funcDecl *dst.FuncDecl) dst.Expr {
+	if values := formatValues(cause, funcDecl); values != nil {
+		return values
+	}
+
+	return dst.NewIdent("nil")
+}
+
+// valueNames returns names of values in the values argument of the constructor call.
+func valueNames(expr dst.Expr) []string {
+	lit, ok := expr.(*dst.CompositeLit)
+	if !ok {
+		return nil
+	}
+
+	names := make([]string, 0, len(lit.Elts))
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*dst.KeyValueExpr)
+		if !ok {
+			return nil
+		}
+		key, ok := kv.Key.(*dst.BasicLit)
+		if !ok {
+			return nil
+		}
+		names = append(names, key.Value)
+	}
+
+	return names
+}
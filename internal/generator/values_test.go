@@ -0,0 +1,86 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatVerbs(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+		ok     bool
+	}{
+		{"user %d not found: %w", "dw", true},
+		{"100%% of %s", "s", true},
+		{"%-8s|%+.2f|%#v|%08x", "sfvx", true},
+		{"no verbs", "", true},
+		{"trailing %", "", true},
+		{"%[1]d", "", false},
+		{"%*d", "", false},
+	}
+
+	for _, tt := range tests {
+		verbs, ok := formatVerbs(tt.format)
+		if string(verbs) != tt.want || ok != tt.ok {
+			t.Errorf("formatVerbs(%q) = %q, %v, want %q, %v", tt.format, string(verbs), ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestAnalyzeFunctionsFormatValues(t *testing.T) {
+	src := `package a
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Repo struct{ table string }
+
+func (r *Repo) Find(id int) error {
+	key := fmt.Sprint(id)
+	if err := r.get(key); err != nil {
+		return fmt.Errorf("user %d not found in %s by %q: %w", id, r.table, key, err)
+	}
+	if id < 0 {
+		return fmt.Errorf("bad id %d", id)
+	}
+
+	return errors.New("not implemented")
+}
+
+func Plain(id int) error {
+	return fmt.Errorf("bad id %d", id)
+}
+`
+	out, functions := analyze(t, src, Config{})
+
+	values := make(map[string]bool)
+	for _, f := range functions {
+		values[f.FunctionName] = f.Values
+	}
+	if !values["Find"] || values["Plain"] {
+		t.Errorf("Values = %v, want only Find", values)
+	}
+
+	for _, want := range []string{
+		`return NewRepoFindError(id, map[string]any{"r.table": r.table, "key": key}, "r.get", fmt.Errorf(`,
+		`return NewRepoFindError(id, nil, "unknown error in Find", fmt.Errorf("bad id %d", id))`,
+		`return NewRepoFindError(id, nil, "unknown error in Find", ErrNotImplemented)`,
+		`return NewPlainError(id, "unknown error in Plain", fmt.Errorf("bad id %d", id))`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output has no %q:\n%s", want, out)
+		}
+	}
+
+	// Calls without values are migrated, others are kept
+	migrated := strings.Replace(out, `map[string]any{"r.table": r.table, "key": key}, `, "", 1)
+	if again, _ := analyze(t, migrated, Config{}); again != out {
+		t.Errorf("wrapper call without values is not migrated:\n%s", again)
+	}
+	if again, _ := analyze(t, out, Config{}); again != out {
+		t.Errorf("second run changes the output:\n%s", again)
+	}
+}
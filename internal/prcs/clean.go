@@ -0,0 +1,475 @@
+package prcs
+
+import (
+	"bytes"
+	"fmt"
+	"go/token"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Bionic2113/errgen/internal/generator"
+	"github.com/Bionic2113/errgen/pkg/utils"
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator"
+	"github.com/dave/dst/dstutil"
+	"golang.org/x/tools/go/packages"
+)
+
+var generatedHeaders = [][]byte{
+	[]byte("// Code generated by errgen. DO NOT EDIT."),
+	[]byte("// Code generated by stringer. DO NOT EDIT."),
+}
+
+// Cleaner removes everything which was generated by errgen:
+// constructor calls are replaced with the wrapped errors
+// and generated files are deleted.
+type Cleaner struct {
+	currentDir        string
+	patterns          []string
+	collectorFilename string
+	wrapperFilename   string
+	stringerFilename  string
+	fs                utils.FileSystem
+	warnings          []string
+	// importers are packages which import the package of the directory
+	importers map[string]importers
+}
+
+// importers are files of other packages which import the package.
+type importers struct {
+	importPath string
+	name       string
+	files      []string
+}
+
+func NewCleaner(
+	currentDir string,
+	patterns []string,
+	collectorFilename string,
+	wrapperFilename string,
+	stringerFilename string,
+	fs utils.FileSystem,
+) *Cleaner {
+	return &Cleaner{
+		currentDir:        currentDir,
+		patterns:          patterns,
+		collectorFilename: collectorFilename,
+		wrapperFilename:   wrapperFilename,
+		stringerFilename:  stringerFilename,
+		fs:                fs,
+	}
+}
+
+// Warnings returns problems which were found during cleaning,
+// but did not prevent it.
+func (c *Cleaner) Warnings() []string {
+	return c.warnings
+}
+
+func (c *Cleaner) Clean() error {
+	dirs := make(map[string][]string)
+	err := walkPatterns(c.currentDir, c.patterns, func(path string) error {
+		dir := filepath.Dir(path)
+		dirs[dir] = append(dirs[dir], path)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	c.importers = loadImporters(c.currentDir)
+	for _, dir := range slices.Sorted(maps.Keys(dirs)) {
+		if err := c.cleanDir(dir, dirs[dir]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *Cleaner) cleanDir(dir string, files []string) error {
+	wrapperPath := filepath.Join(dir, c.wrapperFilename+".go")
+	collectorPath := filepath.Join(dir, c.collectorFilename+".go")
+	stringerPath := filepath.Join(dir, c.stringerFilename+".go")
+
+	constructors, err := c.constructors(wrapperPath)
+	if err != nil {
+		return err
+	}
+
+	sentinels, err := c.sentinels(collectorPath)
+	if err != nil {
+		return err
+	}
+
+	usedSentinels := false
+	for _, path := range files {
+		if path == wrapperPath || path == collectorPath || path == stringerPath {
+			continue
+		}
+
+		used, err := c.cleanFile(path, constructors, sentinels)
+		if err != nil {
+			return err
+		}
+		usedSentinels = usedSentinels || used
+	}
+
+	user := ""
+	if !usedSentinels && len(sentinels) > 0 {
+		user, err = c.importedSentinels(dir, sentinels)
+		if err != nil {
+			return err
+		}
+	}
+
+	generated := []string{wrapperPath, stringerPath}
+	switch {
+	case usedSentinels:
+		c.warnings = append(c.warnings, collectorPath+": sentinel errors are still used, file is kept")
+	case user != "":
+		c.warnings = append(c.warnings, collectorPath+": sentinel errors are still used by "+user+", file is kept")
+	default:
+		generated = append(generated, collectorPath)
+	}
+
+	for _, path := range generated {
+		if !slices.Contains(files, path) || !c.isGenerated(path) {
+			continue
+		}
+
+		if err := c.fs.Remove(path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadImporters returns importers of every package in dir and below by the package
+// directory. Nothing is returned when packages can not be loaded, packages can not
+// import each other then either.
+func loadImporters(dir string) map[string]importers {
+	cfg := &packages.Config{Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports, Dir: dir, Tests: true}
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		return nil
+	}
+
+	result := make(map[string]importers)
+	dirs := make(map[string]string)
+	for _, pkg := range pkgs {
+		if len(pkg.GoFiles) > 0 {
+			d := filepath.Dir(pkg.GoFiles[0])
+			dirs[pkg.PkgPath] = d
+			result[d] = importers{importPath: pkg.PkgPath, name: pkg.Name}
+		}
+	}
+
+	for _, pkg := range pkgs {
+		for path := range pkg.Imports {
+			d, ok := dirs[path]
+			if !ok || path == pkg.PkgPath {
+				continue
+			}
+
+			imp := result[d]
+			for _, file := range pkg.GoFiles {
+				if !slices.Contains(imp.files, file) {
+					imp.files = append(imp.files, file)
+				}
+			}
+			result[d] = imp
+		}
+	}
+
+	return result
+}
+
+// importedSentinels returns the file of other package which uses
+// sentinels of the package in dir, empty string is returned when there is none.
+func (c *Cleaner) importedSentinels(dir string, sentinels map[string]generator.Message) (string, error) {
+	imp := c.importers[dir]
+	for _, path := range imp.files {
+		node, err := c.parse(path)
+		if err != nil {
+			return "", err
+		}
+
+		if usesSentinels(node, imp, sentinels) {
+			return path, nil
+		}
+	}
+
+	return "", nil
+}
+
+// usesSentinels reports whether the file refers to sentinels of the imported package.
+func usesSentinels(node *dst.File, imp importers, sentinels map[string]generator.Message) bool {
+	name := ""
+	for _, spec := range node.Imports {
+		if strings.Trim(spec.Path.Value, "`\"") != imp.importPath {
+			continue
+		}
+
+		name = imp.name
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+	}
+
+	if name == "" || name == "_" {
+		return false
+	}
+
+	var used bool
+	var inspect func(n dst.Node) bool
+	inspect = func(n dst.Node) bool {
+		switch v := n.(type) {
+		case *dst.SelectorExpr:
+			if x, ok := v.X.(*dst.Ident); ok && x.Name == name {
+				_, ok := sentinels[v.Sel.Name]
+				used = used || ok
+			}
+			// Sel belongs to other package
+			dst.Inspect(v.X, inspect)
+			return false
+		case *dst.Ident:
+			// Dot import
+			if name == "." {
+				_, ok := sentinels[v.Name]
+				used = used || ok
+			}
+		}
+		return !used
+	}
+	dst.Inspect(node, inspect)
+
+	return used
+}
+
+func (c *Cleaner) isGenerated(path string) bool {
+	src, err := c.fs.ReadFile(path)
+	if err != nil {
+		return false
+	}
+
+	for _, header := range generatedHeaders {
+		if bytes.HasPrefix(src, header) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// constructors returns names of functions from the wrappers file
+// which create wrappers, whatever naming scheme was used.
+func (c *Cleaner) constructors(path string) (map[string]struct{}, error) {
+	constructors := make(map[string]struct{})
+	if !c.isGenerated(path) {
+		return constructors, nil
+	}
+
+	node, err := c.parse(path)
+	if err != nil {
+		return nil, err
+	}
+
+	wrappers := generator.WrapperTypes(node)
+	for _, decl := range node.Decls {
+		funcDecl, ok := decl.(*dst.FuncDecl)
+		if !ok || funcDecl.Recv != nil || funcDecl.Type.Results == nil || len(funcDecl.Type.Results.List) != 1 {
+			continue
+		}
+
+		star, ok := funcDecl.Type.Results.List[0].Type.(*dst.StarExpr)
+		if !ok {
+			continue
+		}
+
+		if ident, ok := star.X.(*dst.Ident); ok {
+			if _, ok := wrappers[ident.Name]; ok {
+				constructors[funcDecl.Name.Name] = struct{}{}
+			}
+		}
+	}
+
+	return constructors, nil
+}
+
+// sentinels returns messages of generated errors by their names.
+func (c *Cleaner) sentinels(path string) (map[string]generator.Message, error) {
+	sentinels := make(map[string]generator.Message)
+	if !c.isGenerated(path) {
+		return sentinels, nil
+	}
+
+	node, err := c.parse(path)
+	if err != nil {
+		return nil, err
+	}
+
+	dst.Inspect(node, func(n dst.Node) bool {
+		val, ok := n.(*dst.ValueSpec)
+		if !ok || len(val.Names) < 1 || len(val.Values) < 1 {
+			return true
+		}
+
+		if msg, ok := generator.ConstantMessage(val.Values[0]); ok {
+			sentinels[val.Names[0].Name] = msg
+		}
+
+		return true
+	})
+
+	return sentinels, nil
+}
+
+func (c *Cleaner) parse(path string) (*dst.File, error) {
+	src, err := c.fs.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	node, _, err := utils.ParseFile(token.NewFileSet(), path, src)
+	return node, err
+}
+
+// cleanFile replaces constructor calls with their error argument
+// and reports whether sentinel errors are still used in the file.
+func (c *Cleaner) cleanFile(path string, constructors map[string]struct{}, sentinels map[string]generator.Message) (bool, error) {
+	node, err := c.parse(path)
+	if err != nil {
+		return false, err
+	}
+
+	var changed bool
+	needImports := make(map[string]bool)
+	dstutil.Apply(node, nil, func(cursor *dstutil.Cursor) bool {
+		call, ok := cursor.Node().(*dst.CallExpr)
+		if !ok || len(call.Args) == 0 {
+			return true
+		}
+
+		ident, ok := call.Fun.(*dst.Ident)
+		if !ok {
+			return true
+		}
+
+		if _, ok := constructors[ident.Name]; !ok {
+			return true
+		}
+
+		errArg := call.Args[len(call.Args)-1]
+		if sentinel, ok := errArg.(*dst.Ident); ok {
+			if msg, ok := sentinels[sentinel.Name]; ok {
+				pkg, fun := "errors", "New"
+				if msg.Format {
+					pkg, fun = "fmt", "Errorf"
+				}
+				errArg = &dst.CallExpr{
+					Fun:  &dst.SelectorExpr{X: dst.NewIdent(pkg), Sel: dst.NewIdent(fun)},
+					Args: []dst.Expr{&dst.BasicLit{Kind: token.STRING, Value: `"` + msg.Text + `"`}},
+				}
+				needImports[pkg] = true
+			}
+		}
+
+		errArg.Decorations().Before = call.Decs.Before
+		errArg.Decorations().After = call.Decs.After
+		cursor.Replace(errArg)
+		changed = true
+
+		return true
+	})
+
+	if changed {
+		removeGuards(node)
+	}
+
+	usedSentinels := false
+	var inspect func(n dst.Node) bool
+	inspect = func(n dst.Node) bool {
+		switch v := n.(type) {
+		case *dst.SelectorExpr:
+			// Sel belongs to other package
+			dst.Inspect(v.X, inspect)
+			return false
+		case *dst.Ident:
+			if _, ok := sentinels[v.Name]; ok {
+				usedSentinels = true
+			}
+		}
+		return true
+	}
+	dst.Inspect(node, inspect)
+
+	if !changed {
+		return usedSentinels, nil
+	}
+
+	for _, pkg := range slices.Sorted(maps.Keys(needImports)) {
+		utils.AddImport(node, pkg)
+	}
+
+	var buf bytes.Buffer
+	if err := decorator.Fprint(&buf, node); err != nil {
+		return false, fmt.Errorf("print %s: %w", path, err)
+	}
+
+	return usedSentinels, c.fs.WriteFile(path, buf.Bytes())
+}
+
+// removeGuards restores "err = f.Close()" of deferred functions from
+// "if err = f.Close(); err != nil { err = err }" left after unwrapping
+// and removes "if err != nil { err = err }" before naked returns.
+func removeGuards(node *dst.File) {
+	dstutil.Apply(node, nil, func(cursor *dstutil.Cursor) bool {
+		ifStmt, ok := cursor.Node().(*dst.IfStmt)
+		if !ok || ifStmt.Else != nil || len(ifStmt.Body.List) != 1 {
+			return true
+		}
+
+		init, ok := ifStmt.Init.(*dst.AssignStmt)
+		if ifStmt.Init != nil && (!ok || init.Tok != token.ASSIGN) {
+			return true
+		}
+
+		self, ok := ifStmt.Body.List[0].(*dst.AssignStmt)
+		if !ok || len(self.Lhs) != 1 || len(self.Rhs) != 1 || self.Tok != token.ASSIGN {
+			return true
+		}
+
+		lhs, ok := self.Lhs[0].(*dst.Ident)
+		if !ok {
+			return true
+		}
+		if rhs, ok := self.Rhs[0].(*dst.Ident); !ok || rhs.Name != lhs.Name {
+			return true
+		}
+
+		cond, ok := ifStmt.Cond.(*dst.BinaryExpr)
+		if !ok || cond.Op != token.NEQ || !generator.IsNilError(cond.Y) {
+			return true
+		}
+		if x, ok := cond.X.(*dst.Ident); !ok || x.Name != lhs.Name {
+			return true
+		}
+
+		if init == nil {
+			if cursor.Index() >= 0 {
+				cursor.Delete()
+			}
+			return true
+		}
+
+		init.Decs.Before, init.Decs.Start, init.Decs.After = ifStmt.Decs.Before, ifStmt.Decs.Start, ifStmt.Decs.After
+		init.Decs.End = self.Decs.End
+		cursor.Replace(init)
+
+		return true
+	})
+}
@@ -0,0 +1,200 @@
+package prcs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Bionic2113/errgen/internal/vfs"
+)
+
+const cleanWrappers = `// Code generated by errgen. DO NOT EDIT.
+package a
+
+type GetError struct {
+	id           int
+	reasonErrGen string
+	errErrGen    error
+}
+
+func NewGetError(id int, reasonErrGen string, errErrGen error) *GetError {
+	return &GetError{id: id, reasonErrGen: reasonErrGen, errErrGen: errErrGen}
+}
+
+func (e *GetError) Error() string {
+	return e.reasonErrGen + ": " + e.errErrGen.Error()
+}
+`
+
+const cleanSentinels = `// Code generated by errgen. DO NOT EDIT.
+package a
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrIDIsZero     = errors.New("id is zero")
+	ErrIDIsNegative = fmt.Errorf("id is negative")
+)
+`
+
+const cleanSource = `package a
+
+import "os"
+
+// Get opens the file.
+func Get(id int) (err error) {
+	f, err := os.Open("a.txt")
+	if err != nil {
+		return NewGetError(id, "os.Open", err)
+	}
+	defer func() {
+		if err = f.Close(); err != nil {
+			err = NewGetError(id, "f.Close", err)
+		}
+	}()
+
+	if id == 0 {
+		return NewGetError(id, "unknown error in Get", ErrIDIsZero)
+	}
+	if id < 0 {
+		return NewGetError(id, "unknown error in Get", ErrIDIsNegative) //errgen:reason "negative"
+	}
+
+	return nil
+}
+`
+
+const cleanResult = `package a
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Get opens the file.
+func Get(id int) (err error) {
+	f, err := os.Open("a.txt")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = f.Close()
+	}()
+
+	if id == 0 {
+		return errors.New("id is zero")
+	}
+	if id < 0 {
+		return fmt.Errorf("id is negative") //errgen:reason "negative"
+	}
+
+	return nil
+}
+`
+
+// writeTree writes files into the temporary directory.
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+// clean runs the cleaner on dir and returns its changes by relative paths.
+func clean(t *testing.T, dir string) (map[string]vfs.Change, []string) {
+	t.Helper()
+
+	overlay := vfs.NewOverlay()
+	cleaner := NewCleaner(dir, []string{"./..."}, "error_gen", "errwrap_gen", "strings", overlay)
+	if err := cleaner.Clean(); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := overlay.Changes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes := make(map[string]vfs.Change, len(list))
+	for _, c := range list {
+		rel, err := filepath.Rel(dir, c.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		changes[filepath.ToSlash(rel)] = c
+	}
+
+	return changes, cleaner.Warnings()
+}
+
+func TestClean(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"go.mod":           "module example.com/m\n\ngo 1.22\n",
+		"a/a.go":           cleanSource,
+		"a/errwrap_gen.go": cleanWrappers,
+		"a/error_gen.go":   cleanSentinels,
+	})
+
+	changes, warnings := clean(t, dir)
+	if len(warnings) > 0 {
+		t.Errorf("warnings: %v", warnings)
+	}
+
+	if got := string(changes["a/a.go"].After); got != cleanResult {
+		t.Errorf("a/a.go is cleaned to:\n%s\nwant:\n%s", got, cleanResult)
+	}
+
+	for _, name := range []string{"a/errwrap_gen.go", "a/error_gen.go"} {
+		if changes[name].Kind != vfs.Removed {
+			t.Errorf("%s is %q, want removed", name, changes[name].Kind)
+		}
+	}
+}
+
+func TestCleanKeepsImportedSentinels(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"go.mod":           "module example.com/m\n\ngo 1.22\n",
+		"a/a.go":           cleanSource,
+		"a/errwrap_gen.go": cleanWrappers,
+		"a/error_gen.go":   cleanSentinels,
+		"b/b.go": `package b
+
+import (
+	"errors"
+
+	alias "example.com/m/a"
+)
+
+func IsZero(err error) bool {
+	return errors.Is(err, alias.ErrIDIsZero)
+}
+`,
+	})
+
+	changes, warnings := clean(t, dir)
+	if _, ok := changes["a/error_gen.go"]; ok {
+		t.Error("a/error_gen.go is changed, but its sentinels are used by the package b")
+	}
+	if changes["a/errwrap_gen.go"].Kind != vfs.Removed {
+		t.Errorf("a/errwrap_gen.go is %q, want removed", changes["a/errwrap_gen.go"].Kind)
+	}
+
+	if len(warnings) != 1 || !strings.Contains(warnings[0], filepath.Join("b", "b.go")) {
+		t.Errorf("warnings are %v, want the warning about b/b.go", warnings)
+	}
+}
@@ -1,121 +1,364 @@
 package prcs
 
 import (
-	"go/parser"
+	"bytes"
+	"cmp"
+	"fmt"
+	"go/ast"
 	"go/token"
-	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"text/template"
 
 	"github.com/Bionic2113/errgen/internal/collector"
 	"github.com/Bionic2113/errgen/internal/generator"
+	"github.com/Bionic2113/errgen/internal/report"
 	"github.com/Bionic2113/errgen/pkg/utils"
 	"github.com/dave/dst"
 	"github.com/dave/dst/decorator"
+	"golang.org/x/tools/go/packages"
 )
 
 type FileProcessor struct {
-	packages          map[utils.PkgInfo][]utils.FunctionInfo
+	packages map[utils.PkgInfo][]utils.FunctionInfo
+	typed    map[string]typedFile
+	// names are functions by their wrapper type names in every package
+	names             map[utils.PkgInfo]map[string]string
+	namer             *generator.Namer
+	genCfg            generator.Config
+	wrapperTmpl       *template.Template
+	unwrapped         []Unwrapped
+	report            *report.Report
 	currentDir        string
+	patterns          []string
 	collectorFilename string
 	wrapperFilename   string
+	fs                utils.FileSystem
 	collector         *collector.ErrorCollector
 	stringer          Stringer
 	skipper           Skipper
 }
 
+// Unwrapped is the error return which was not wrapped
+// by the constructor before processing.
+type Unwrapped struct {
+	Pos         token.Position
+	Constructor string
+	// Stale reports whether the return is wrapped by the constructor
+	// of the old name or arguments, which is migrated to Constructor.
+	Stale bool
+}
+
+// typedFile is the file loaded with type information.
+type typedFile struct {
+	pkg  *packages.Package
+	file *ast.File
+}
+
 type Stringer interface {
-	MakeStringFuncs(pkgInfo utils.PkgInfo, scope *dst.Scope)
-	GenerateFiles() error
+	MakeStringFuncs(pkgInfo utils.PkgInfo, scope *dst.Scope) error
+	GenerateFiles(fs utils.FileSystem) error
 }
 
 type Skipper interface {
-	NeedSkipField(name, path string) bool
-	ModuleName(path string) string
+	utils.Skipper
 	NeedSkipFile(path string) bool
 }
 
+// New creates processor for sources in currentDir.
+// Patterns are relative to currentDir: "./..." means all subdirectories,
+// "./pkg/..." - pkg and its subdirectories, "./pkg" - only pkg directory.
 func New(
+	currentDir string,
+	patterns []string,
 	collectorFilename string,
 	wrapperFilename string,
+	genCfg generator.Config,
+	fs utils.FileSystem,
 	st Stringer,
 	sk Skipper,
 ) (*FileProcessor, error) {
-	currentDir, err := os.Getwd()
+	if err := genCfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	namer, err := generator.NewNamer(genCfg.Naming)
+	if err != nil {
+		return nil, err
+	}
+
+	wrapperTmpl, err := generator.LoadWrapperTemplate(genCfg.WrapperTemplate)
 	if err != nil {
 		return nil, err
 	}
 
-	c, err := collector.New(collectorFilename)
+	sentinelTmpl, err := collector.LoadTemplate(genCfg.SentinelTemplate)
 	if err != nil {
 		return nil, err
 	}
 
+	rep := &report.Report{}
+
+	// Sentinels are loaded before sources are processed, so new ones get
+	// next names. Collector is usable even if some files were not loaded
+	c := collector.New(collectorFilename, sentinelTmpl, genCfg.Sentinel)
+	err = walkPatterns(currentDir, patterns, func(path string) error {
+		if strings.HasSuffix(path, collectorFilename+".go") {
+			rep.Add(report.Collect, path, c.ProcessFile(currentDir, path))
+		}
+		return nil
+	})
+	rep.Add(report.Collect, currentDir, err)
+
 	return &FileProcessor{
+		report:            rep,
 		currentDir:        currentDir,
+		patterns:          patterns,
 		collectorFilename: collectorFilename,
 		wrapperFilename:   wrapperFilename,
+		fs:                fs,
 		packages:          make(map[utils.PkgInfo][]utils.FunctionInfo),
+		names:             make(map[utils.PkgInfo]map[string]string),
+		namer:             namer,
+		genCfg:            genCfg,
+		wrapperTmpl:       wrapperTmpl,
 		collector:         c,
 		stringer:          st,
 		skipper:           sk,
 	}, nil
 }
 
+// ProcessFiles rewrites all matched files. Problems with separate files
+// do not stop processing, they are returned by GenerateErrorFiles.
 func (p *FileProcessor) ProcessFiles() error {
-	return filepath.Walk(p.currentDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
+	p.loadTypes()
 
-		// Пропускаем директории, тесты, файлы с ошибками и main.go
-		if info.IsDir() ||
-			!strings.HasSuffix(path, ".go") ||
-			strings.HasSuffix(path, p.wrapperFilename+".go") ||
+	return walkPatterns(p.currentDir, p.patterns, func(path string) error {
+		// Пропускаем тесты, файлы с ошибками и main.go
+		if strings.HasSuffix(path, p.wrapperFilename+".go") ||
 			strings.HasSuffix(path, p.collectorFilename+".go") ||
 			p.skipper.NeedSkipFile(path) {
 			return nil
 		}
 
-		return p.ProcessFile(path)
+		p.report.Add(report.Analyze, path, p.ProcessFile(path))
+
+		return nil
 	})
 }
 
+// loadTypes loads packages matched by patterns with type information.
+// Packages which could not be loaded are processed by the syntax only,
+// so errors are not reported here.
+func (p *FileProcessor) loadTypes() {
+	p.typed = make(map[string]typedFile)
+
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles |
+			packages.NeedImports | packages.NeedDeps | packages.NeedTypes |
+			packages.NeedSyntax | packages.NeedTypesInfo,
+		Dir: p.currentDir,
+	}
+
+	pkgs, err := packages.Load(cfg, p.patterns...)
+	if err != nil {
+		return
+	}
+
+	for _, pkg := range pkgs {
+		if pkg.Types == nil || pkg.TypesInfo == nil || len(pkg.Syntax) != len(pkg.CompiledGoFiles) || hasParseErrors(pkg) {
+			continue
+		}
+
+		for i, file := range pkg.Syntax {
+			p.typed[filepath.Clean(pkg.CompiledGoFiles[i])] = typedFile{pkg: pkg, file: file}
+		}
+	}
+}
+
+// hasParseErrors reports whether the package has syntax errors, such files
+// are parsed again to report the errors.
+func hasParseErrors(pkg *packages.Package) bool {
+	for _, err := range pkg.Errors {
+		if err.Kind == packages.ParseError {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parse returns decorated file and its types when they are known.
+func (p *FileProcessor) parse(path string) (*dst.File, *decorator.Decorator, utils.TypeResolver, error) {
+	if t, ok := p.typed[filepath.Clean(path)]; ok {
+		dec := decorator.NewDecorator(t.pkg.Fset)
+		node, err := dec.DecorateFile(t.file)
+		if err == nil {
+			return node, dec, utils.NewTypeResolver(dec, t.pkg.TypesInfo, t.pkg.Types), nil
+		}
+	}
+
+	src, err := p.fs.ReadFile(path)
+	if err != nil {
+		return nil, nil, nil, report.File(report.Parse, path, err)
+	}
+
+	node, dec, err := utils.ParseFile(token.NewFileSet(), path, src)
+	if err != nil {
+		var rep report.Report
+		rep.Add(report.Parse, path, err)
+		return nil, nil, nil, rep.Err()
+	}
+
+	return node, dec, nil, nil
+}
+
 func (p *FileProcessor) ProcessFile(path string) error {
-	fset := token.NewFileSet()
-	node, err := decorator.ParseFile(fset, path, nil, parser.ParseComments)
+	node, dec, resolver, err := p.parse(path)
 	if err != nil {
 		return err
 	}
 
 	pkgInfo := utils.PkgInfo{Name: node.Name.Name, Path: filepath.Dir(path)}
 
-	p.stringer.MakeStringFuncs(pkgInfo, node.Scope)
+	optedIn := p.optedIn(pkgInfo.Path)
+	if !generator.IsFileIgnored(node) && generator.OptedIn(node, p.genCfg, optedIn) {
+		if err := p.stringer.MakeStringFuncs(pkgInfo, node.Scope); err != nil {
+			// Stringer is optional for the file, so wrappers are generated anyway
+			p.report.Add(report.Stringer, path, err)
+		}
+	}
+
+	restored := generator.UnwrapIgnored(node, resolver)
 
 	subPkg := utils.SubPackageName(pkgInfo.Path, p.currentDir)
-	fileName := filepath.Base(path)
-	functions := generator.AnalyzeFunctions(
+	functions, rewrites, err := generator.AnalyzeFunctions(
 		node, pkgInfo, subPkg,
-		p.currentDir, fileName, p.collector,
-		p.skipper,
+		p.collector, p.skipper, resolver, p.uniqueNamer(pkgInfo, resolver), p.genCfg, optedIn,
 	)
-	if len(functions) > 0 {
-		p.packages[pkgInfo] = append(p.packages[pkgInfo], functions...)
+	p.report.Add(report.Analyze, path, err)
+
+	for _, r := range rewrites {
+		u := Unwrapped{Pos: token.Position{Filename: path}, Constructor: r.Constructor, Stale: r.Stale}
+		if n, ok := dec.Ast.Nodes[r.Stmt]; ok {
+			u.Pos = dec.Fset.Position(n.Pos())
+		}
+		p.unwrapped = append(p.unwrapped, u)
 	}
 
+	if len(functions) == 0 && !restored {
+		return nil
+	}
+
+	if err := p.write(node, path, functions); err != nil {
+		return report.File(report.Write, path, err)
+	}
+	p.packages[pkgInfo] = append(p.packages[pkgInfo], functions...)
+
 	return nil
 }
 
-func (p *FileProcessor) GenerateErrorFiles() {
-	if err := p.collector.GenerateFiles(); err != nil {
-		panic("[FileProcessor] - GenerateErrorFiles - collector.GenerateFiles: " + err.Error())
+// optedIn reports whether the package in dir is listed
+// in generator.packages for the opt-in mode.
+func (p *FileProcessor) optedIn(dir string) bool {
+	for _, pattern := range p.genCfg.Packages {
+		root, recursive := patternRoot(p.currentDir, pattern)
+		if dir == root || (recursive && strings.HasPrefix(dir, root+string(filepath.Separator))) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// write writes the modified file, return sites are stamped
+// into constructor calls when locations are enabled.
+func (p *FileProcessor) write(node *dst.File, path string, functions []utils.FunctionInfo) error {
+	if !p.genCfg.Location {
+		return utils.WriteModifiedFile(p.fs, node, path)
+	}
+
+	var buf bytes.Buffer
+	if err := decorator.Fprint(&buf, node); err != nil {
+		return fmt.Errorf("format modified file: %w", err)
+	}
+
+	name, err := filepath.Rel(p.currentDir, path)
+	if err != nil {
+		return err
+	}
+
+	src, err := generator.StampLocations(buf.Bytes(), filepath.ToSlash(name), functions)
+	if err != nil {
+		return fmt.Errorf("stamp locations: %w", err)
+	}
+
+	return p.fs.WriteFile(path, src)
+}
+
+// uniqueNamer returns the namer of the file in the package. Wrappers
+// which clash with each other or with declarations of the package
+// are reported and not generated.
+func (p *FileProcessor) uniqueNamer(pkgInfo utils.PkgInfo, resolver utils.TypeResolver) *uniqueNamer {
+	names, ok := p.names[pkgInfo]
+	if !ok {
+		names = make(map[string]string)
+		p.names[pkgInfo] = names
+	}
+
+	return &uniqueNamer{namer: p.namer, names: names, resolver: resolver}
+}
+
+type uniqueNamer struct {
+	namer    *generator.Namer
+	names    map[string]string
+	resolver utils.TypeResolver
+}
+
+func (n *uniqueNamer) TypeName(f utils.FunctionInfo) (string, error) {
+	typeName, err := n.namer.TypeName(f)
+	if err != nil {
+		return "", err
 	}
 
-	if err := p.stringer.GenerateFiles(); err != nil {
-		panic("[FileProcessor] - GenerateErrorFiles - stringer.GenerateFiles: " + err.Error())
+	name := generator.QualifiedName(f)
+	if other, ok := n.names[typeName]; ok {
+		return "", fmt.Errorf("wrapper %s of %s clashes with the wrapper of %s, change generator.naming", typeName, name, other)
 	}
 
-	for pkg, functions := range p.packages {
-		generator.GenerateErrorFile(p.wrapperFilename, pkg, functions)
+	if declared := generator.DeclaredName(typeName, n.resolver); declared != "" {
+		return "", fmt.Errorf("wrapper %s of %s clashes with declared %s, change generator.naming", typeName, name, declared)
 	}
+	n.names[typeName] = name
+
+	return typeName, nil
+}
+
+// Unwrapped returns error returns which were wrapped during processing
+// sorted by their positions.
+func (p *FileProcessor) Unwrapped() []Unwrapped {
+	slices.SortFunc(p.unwrapped, func(a, b Unwrapped) int {
+		return cmp.Or(
+			cmp.Compare(a.Pos.Filename, b.Pos.Filename),
+			cmp.Compare(a.Pos.Line, b.Pos.Line),
+			cmp.Compare(a.Pos.Column, b.Pos.Column),
+		)
+	})
+
+	return p.unwrapped
+}
+
+// GenerateErrorFiles generates files for all processed packages
+// and returns the report of all problems found during the run.
+func (p *FileProcessor) GenerateErrorFiles() error {
+	p.report.Add(report.Generate, "", p.collector.GenerateFiles(p.fs))
+	p.report.Add(report.Stringer, "", p.stringer.GenerateFiles(p.fs))
+
+	for _, pkg := range utils.SortedPackages(p.packages) {
+		err := generator.GenerateErrorFile(p.fs, p.wrapperFilename, pkg, p.packages[pkg], p.genCfg, p.wrapperTmpl)
+		p.report.Add(report.Generate, filepath.Join(pkg.Path, p.wrapperFilename+".go"), err)
+	}
+
+	return p.report.Err()
 }
@@ -0,0 +1,56 @@
+package prcs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// walkPatterns calls fn for every .go file matched by patterns.
+// Patterns are relative to currentDir: "./..." means all subdirectories,
+// "./pkg/..." - pkg and its subdirectories, "./pkg" - only pkg directory.
+func walkPatterns(currentDir string, patterns []string, fn func(path string) error) error {
+	visited := make(map[string]struct{})
+	for _, pattern := range patterns {
+		root, recursive := patternRoot(currentDir, pattern)
+		if err := walkDir(root, recursive, visited, fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func patternRoot(currentDir, pattern string) (string, bool) {
+	recursive := pattern == "..." || strings.HasSuffix(pattern, "/...")
+	pattern = strings.TrimSuffix(strings.TrimSuffix(pattern, "..."), "/")
+
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(currentDir, pattern)
+	}
+
+	return filepath.Clean(pattern), recursive
+}
+
+func walkDir(root string, recursive bool, visited map[string]struct{}, fn func(path string) error) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() && !recursive && path != root {
+			return filepath.SkipDir
+		}
+
+		if _, ok := visited[path]; ok {
+			return nil
+		}
+		visited[path] = struct{}{}
+
+		if info.IsDir() || !strings.HasSuffix(path, ".go") {
+			return nil
+		}
+
+		return fn(path)
+	})
+}
@@ -0,0 +1,116 @@
+package report
+
+import (
+	"errors"
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"strings"
+)
+
+// Stage is the step of processing where the error happened.
+type Stage string
+
+const (
+	Parse    Stage = "parse"
+	Analyze  Stage = "analyze"
+	Write    Stage = "write"
+	Collect  Stage = "collect"
+	Stringer Stage = "stringer"
+	Generate Stage = "generate"
+	Verify   Stage = "verify"
+)
+
+// Error is the problem with one file on one stage of processing.
+type Error struct {
+	Stage Stage
+	Pos   token.Position
+	Err   error
+}
+
+// At creates error for the file position.
+func At(stage Stage, pos token.Position, err error) *Error {
+	return &Error{Stage: stage, Pos: pos, Err: err}
+}
+
+// File creates error for the whole file.
+func File(stage Stage, filename string, err error) *Error {
+	return At(stage, token.Position{Filename: filename}, err)
+}
+
+func (e *Error) Error() string {
+	return e.Pos.String() + ": " + string(e.Stage) + ": " + e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Report collects errors of all files, so one bad file
+// does not abort processing of others.
+type Report struct {
+	errs []*Error
+}
+
+// Add adds err to the report. Errors which are already *Error keep their
+// stage and position, parse errors are split by their positions,
+// others get stage and filename from arguments.
+func (r *Report) Add(stage Stage, filename string, err error) {
+	if err == nil {
+		return
+	}
+
+	if e, ok := err.(*Error); ok {
+		r.errs = append(r.errs, e)
+		return
+	}
+
+	var list scanner.ErrorList
+	if errors.As(err, &list) {
+		for _, item := range list {
+			r.errs = append(r.errs, At(stage, item.Pos, errors.New(item.Msg)))
+		}
+		return
+	}
+
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, err := range joined.Unwrap() {
+			r.Add(stage, filename, err)
+		}
+		return
+	}
+
+	r.errs = append(r.errs, File(stage, filename, err))
+}
+
+func (r *Report) Errors() []*Error {
+	return r.errs
+}
+
+// Err returns nil when there are no errors and the report itself otherwise.
+func (r *Report) Err() error {
+	if len(r.errs) == 0 {
+		return nil
+	}
+
+	return r
+}
+
+func (r *Report) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d error(s):", len(r.errs))
+	for _, e := range r.errs {
+		b.WriteString("\n\t" + e.Error())
+	}
+
+	return b.String()
+}
+
+func (r *Report) Unwrap() []error {
+	errs := make([]error, len(r.errs))
+	for i, e := range r.errs {
+		errs[i] = e
+	}
+
+	return errs
+}
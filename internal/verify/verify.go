@@ -0,0 +1,170 @@
+package verify
+
+import (
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Bionic2113/errgen/internal/report"
+	"github.com/Bionic2113/errgen/internal/vfs"
+	"golang.org/x/tools/go/packages"
+)
+
+// ErrTypesUnavailable is returned when packages can not be type-checked
+// at all, for example without go command or outside of a module.
+var ErrTypesUnavailable = errors.New("type information is unavailable")
+
+const loadMode = packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps |
+	packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo
+
+// Changes checks that changed Go files parse and do not bring
+// new type errors to their packages and to packages which import them.
+// Errors which existed before the changes (missing dependencies and so on)
+// are ignored.
+func Changes(dir string, changes []vfs.Change) error {
+	rep := &report.Report{}
+	overlay := make(map[string][]byte)
+	dirs := make(map[string]struct{})
+
+	fset := token.NewFileSet()
+	for _, c := range changes {
+		if !strings.HasSuffix(c.Path, ".go") {
+			continue
+		}
+
+		dirs[filepath.Dir(c.Path)] = struct{}{}
+		if c.Kind == vfs.Removed {
+			// The file is still listed by the go command,
+			// so it is replaced by the empty file of the same package.
+			overlay[c.Path] = packageStub(fset, c)
+			continue
+		}
+
+		_, err := parser.ParseFile(fset, c.Path, c.After, parser.AllErrors)
+		rep.Add(report.Verify, c.Path, err)
+		overlay[c.Path] = c.After
+	}
+
+	if err := rep.Err(); err != nil || len(dirs) == 0 {
+		return err
+	}
+
+	if err := addImporters(dir, dirs); err != nil {
+		return fmt.Errorf("%w: %w", ErrTypesUnavailable, err)
+	}
+
+	patterns := make([]string, 0, len(dirs))
+	for d := range dirs {
+		patterns = append(patterns, d)
+	}
+
+	before, err := load(dir, patterns, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrTypesUnavailable, err)
+	}
+
+	after, err := load(dir, patterns, overlay)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrTypesUnavailable, err)
+	}
+
+	known := make(map[string]int, len(before))
+	for _, e := range before {
+		known[e.Msg]++
+	}
+
+	for _, e := range after {
+		if known[e.Msg] > 0 {
+			known[e.Msg]--
+			continue
+		}
+
+		rep.Add(report.Verify, "", report.At(report.Verify, position(e.Pos), errors.New(e.Msg)))
+	}
+
+	return rep.Err()
+}
+
+// packageStub returns the file with the package clause of the removed file only.
+func packageStub(fset *token.FileSet, c vfs.Change) []byte {
+	name := filepath.Base(filepath.Dir(c.Path))
+	if f, err := parser.ParseFile(fset, c.Path, c.Before, parser.PackageClauseOnly); err == nil {
+		name = f.Name.Name
+	}
+
+	return []byte("package " + name + "\n")
+}
+
+// addImporters adds directories of packages which import
+// packages of dirs, they may use removed or renamed declarations.
+func addImporters(dir string, dirs map[string]struct{}) error {
+	pkgs, err := packages.Load(&packages.Config{
+		Mode:  packages.NeedName | packages.NeedFiles | packages.NeedImports,
+		Dir:   dir,
+		Tests: true,
+	}, "./...")
+	if err != nil {
+		return err
+	}
+
+	changed := make(map[string]struct{})
+	for _, pkg := range pkgs {
+		if _, ok := dirs[pkgDir(pkg)]; ok {
+			changed[pkg.PkgPath] = struct{}{}
+		}
+	}
+
+	for _, pkg := range pkgs {
+		for path := range pkg.Imports {
+			if _, ok := changed[path]; ok && pkgDir(pkg) != "" {
+				dirs[pkgDir(pkg)] = struct{}{}
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+func pkgDir(pkg *packages.Package) string {
+	if len(pkg.GoFiles) == 0 {
+		return ""
+	}
+
+	return filepath.Dir(pkg.GoFiles[0])
+}
+
+func load(dir string, patterns []string, overlay map[string][]byte) ([]packages.Error, error) {
+	pkgs, err := packages.Load(&packages.Config{Mode: loadMode, Dir: dir, Overlay: overlay, Tests: true}, patterns...)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []packages.Error
+	for _, pkg := range pkgs {
+		errs = append(errs, pkg.Errors...)
+	}
+
+	return errs, nil
+}
+
+// position parses "file:line:col" of packages.Error.
+func position(pos string) token.Position {
+	var p token.Position
+	parts := strings.Split(pos, ":")
+	for len(parts) > 1 {
+		n, err := strconv.Atoi(parts[len(parts)-1])
+		if err != nil {
+			break
+		}
+		p.Column, p.Line = p.Line, n
+		parts = parts[:len(parts)-1]
+	}
+	p.Filename = strings.Join(parts, ":")
+
+	return p
+}
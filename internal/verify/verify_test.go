@@ -0,0 +1,79 @@
+package verify
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Bionic2113/errgen/internal/vfs"
+)
+
+func TestChanges(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/m\n\ngo 1.22\n",
+		"a/a.go": "package a\n\nfunc Get() error { return nil }\n",
+		"a/x.go": "package a\n\nvar ErrX = Get()\n",
+		"b/b.go": "package b\n\nimport \"example.com/m/a\"\n\nvar Err = a.ErrX\n",
+		// The error which existed before the changes
+		"c/c.go": "package c\n\nvar N int = \"n\"\n",
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	path := func(name string) string { return filepath.Join(dir, name) }
+	tests := []struct {
+		name    string
+		changes []vfs.Change
+		err     string
+	}{
+		{
+			"valid",
+			[]vfs.Change{{Path: path("a/a.go"), Kind: vfs.Modified, After: []byte("package a\n\nfunc Get() error { return Load() }\n\nfunc Load() error { return nil }\n")}},
+			"",
+		},
+		{
+			"old errors are ignored",
+			[]vfs.Change{{Path: path("c/c.go"), Kind: vfs.Modified, After: []byte("package c\n\n// N is broken.\nvar N int = \"n\"\n")}},
+			"",
+		},
+		{
+			"syntax error",
+			[]vfs.Change{{Path: path("a/a.go"), Kind: vfs.Modified, After: []byte("package a\n\nfunc Get() error {\n")}},
+			"a.go",
+		},
+		{
+			"type error",
+			[]vfs.Change{{Path: path("a/a.go"), Kind: vfs.Modified, After: []byte("package a\n\nfunc Get() error { return NewGetError() }\n")}},
+			"undefined: NewGetError",
+		},
+		{
+			"removed file used by importers",
+			[]vfs.Change{{Path: path("a/x.go"), Kind: vfs.Removed, Before: []byte(files["a/x.go"])}},
+			"undefined: a.ErrX",
+		},
+		{
+			"created file",
+			[]vfs.Change{{Path: path("a/y.go"), Kind: vfs.Created, After: []byte("package a\n\nvar ErrY = Get()\n")}},
+			"",
+		},
+	}
+
+	for _, tt := range tests {
+		err := Changes(dir, tt.changes)
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("%s: %v", tt.name, err)
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("%s: error is %v, want %q", tt.name, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package vfs
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// Commit writes all changes to the disk. New contents are written
+// to temporary files first and then renamed over the originals.
+// If any step fails, already applied changes are rolled back.
+func (o *Overlay) Commit() (err error) {
+	changes, err := o.Changes()
+	if err != nil {
+		return err
+	}
+
+	temps := make(map[string]string, len(changes))
+	defer func() {
+		for _, tmp := range temps {
+			os.Remove(tmp)
+		}
+	}()
+
+	for _, c := range changes {
+		if c.Kind == Removed {
+			continue
+		}
+
+		tmp, err := writeTemp(c.Path, c.After)
+		if err != nil {
+			return fmt.Errorf("stage %s: %w", c.Path, err)
+		}
+		temps[c.Path] = tmp
+	}
+
+	applied := make([]Change, 0, len(changes))
+	for _, c := range changes {
+		if c.Kind == Removed {
+			err = os.Remove(c.Path)
+		} else {
+			err = os.Rename(temps[c.Path], c.Path)
+			delete(temps, c.Path)
+		}
+
+		if err != nil {
+			return errors.Join(fmt.Errorf("commit %s: %w", c.Path, err), rollback(applied))
+		}
+		applied = append(applied, c)
+	}
+
+	clear(o.files)
+
+	return nil
+}
+
+// rollback restores the state of files before applied changes.
+func rollback(applied []Change) error {
+	var errs []error
+	for _, c := range slices.Backward(applied) {
+		var err error
+		if c.Kind == Created {
+			err = os.Remove(c.Path)
+		} else {
+			err = writeFile(c.Path, c.Before)
+		}
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("rollback %s: %w", c.Path, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func writeFile(path string, data []byte) error {
+	tmp, err := writeTemp(path, data)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	return nil
+}
+
+// writeTemp writes data to the temporary file in the directory of path,
+// so it can be renamed to path atomically.
+func writeTemp(path string, data []byte) (string, error) {
+	mode := fs.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".errgen-*")
+	if err != nil {
+		return "", err
+	}
+
+	_, err = f.Write(data)
+	err = errors.Join(err, f.Chmod(mode), f.Close())
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+
+	return f.Name(), nil
+}
@@ -0,0 +1,193 @@
+package vfs
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const contextLines = 3
+
+type opKind byte
+
+const (
+	opEqual  opKind = ' '
+	opDelete opKind = '-'
+	opInsert opKind = '+'
+)
+
+type op struct {
+	kind opKind
+	line string
+}
+
+// Unified returns unified diff between before and after texts.
+// Empty string is returned when texts are equal.
+func Unified(from, to, before, after string) string {
+	if before == after {
+		return ""
+	}
+
+	ops := editScript(splitLines(before), splitLines(after))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", from, to)
+
+	for start := 0; start < len(ops); {
+		first := nextChange(ops, start)
+		if first == len(ops) {
+			break
+		}
+
+		// Collect changes while gaps between them fit into context
+		last := first
+		for {
+			next := nextChange(ops, last+1)
+			if next == len(ops) || next-last > 2*contextLines {
+				break
+			}
+			last = next
+		}
+
+		hunkStart := max(first-contextLines, start)
+		hunkEnd := min(last+contextLines+1, len(ops))
+		writeHunk(&b, ops, hunkStart, hunkEnd)
+
+		start = hunkEnd
+	}
+
+	return b.String()
+}
+
+func nextChange(ops []op, from int) int {
+	for i := from; i < len(ops); i++ {
+		if ops[i].kind != opEqual {
+			return i
+		}
+	}
+
+	return len(ops)
+}
+
+func writeHunk(b *strings.Builder, ops []op, start, end int) {
+	// Line numbers before the hunk
+	var aLine, bLine int
+	for _, o := range ops[:start] {
+		if o.kind != opInsert {
+			aLine++
+		}
+		if o.kind != opDelete {
+			bLine++
+		}
+	}
+
+	var aLen, bLen int
+	for _, o := range ops[start:end] {
+		if o.kind != opInsert {
+			aLen++
+		}
+		if o.kind != opDelete {
+			bLen++
+		}
+	}
+
+	if aLen > 0 {
+		aLine++
+	}
+	if bLen > 0 {
+		bLine++
+	}
+
+	fmt.Fprintf(b, "@@ -%d,%d +%d,%d @@\n", aLine, aLen, bLine, bLen)
+	for _, o := range ops[start:end] {
+		b.WriteByte(byte(o.kind))
+		b.WriteString(o.line)
+		if !strings.HasSuffix(o.line, "\n") {
+			b.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// editScript finds the shortest edit script with Myers algorithm.
+func editScript(a, b []string) []op {
+	n, m := len(a), len(b)
+	offset := n + m + 1
+	v := make([]int, 2*offset+1)
+
+	// trace[d] keeps v[-d..d] before the step d
+	var trace [][]int
+
+search:
+	for d := 0; d <= n+m; d++ {
+		trace = append(trace, slices.Clone(v[offset-d:offset+d+1]))
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+
+			v[offset+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	ops := make([]op, 0, n+m)
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		prev := trace[d]
+		at := func(k int) int { return prev[k+d] }
+
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		}
+
+		prevX := at(prevK)
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			ops = append(ops, op{kind: opEqual, line: a[x-1]})
+			x--
+			y--
+		}
+
+		if x == prevX {
+			ops = append(ops, op{kind: opInsert, line: b[y-1]})
+			y--
+		} else {
+			ops = append(ops, op{kind: opDelete, line: a[x-1]})
+			x--
+		}
+	}
+
+	for x > 0 && y > 0 {
+		ops = append(ops, op{kind: opEqual, line: a[x-1]})
+		x--
+		y--
+	}
+
+	slices.Reverse(ops)
+
+	return ops
+}
@@ -0,0 +1,100 @@
+package vfs
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name          string
+		before, after string
+		want          string
+	}{
+		{
+			name:   "equal",
+			before: "a\nb\n",
+			after:  "a\nb\n",
+			want:   "",
+		},
+		{
+			name:   "created",
+			before: "",
+			after:  "a\nb\n",
+			want:   "--- from\n+++ to\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name:   "changed line with context",
+			before: "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			after:  "1\n2\n3\n4\nfive\n6\n7\n8\n9\n",
+			want:   "--- from\n+++ to\n@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+		},
+		{
+			name:   "distant changes are separate hunks",
+			before: "a\n1\n2\n3\n4\n5\n6\n7\nb\n",
+			after:  "A\n1\n2\n3\n4\n5\n6\n7\nB\n",
+			want: "--- from\n+++ to\n@@ -1,4 +1,4 @@\n-a\n+A\n 1\n 2\n 3\n" +
+				"@@ -6,4 +6,4 @@\n 5\n 6\n 7\n-b\n+B\n",
+		},
+		{
+			name:   "no newline at end of file",
+			before: "a\nb",
+			after:  "a\nc",
+			want:   "--- from\n+++ to\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+c\n\\ No newline at end of file\n",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := Unified("from", "to", tt.before, tt.after); got != tt.want {
+			t.Errorf("%s:\ngot:\n%s\nwant:\n%s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestOverlayDiff(t *testing.T) {
+	dir := t.TempDir()
+	for name, data := range map[string]string{"kept.go": "package a\n", "old.go": "package a\n", "mod.go": "package a\n"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	o := NewOverlay()
+	if err := o.WriteFile(filepath.Join(dir, "kept.go"), []byte("package a\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.WriteFile(filepath.Join(dir, "mod.go"), []byte("package b\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.WriteFile(filepath.Join(dir, "new.go"), []byte("package a\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Remove(filepath.Join(dir, "old.go")); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := o.Diff(&buf, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	want := strings.Join([]string{
+		"--- a/mod.go", "+++ b/mod.go", "@@ -1,1 +1,1 @@", "-package a", "+package b",
+		"--- /dev/null", "+++ b/new.go", "@@ -0,0 +1,1 @@", "+package a",
+		"--- a/old.go", "+++ /dev/null", "@@ -1,1 +0,0 @@", "-package a",
+		"3 file(s) touched",
+		"\tmodified mod.go",
+		"\tcreated  new.go",
+		"\tremoved  old.go",
+	}, "\n") + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	if _, err := o.ReadFile(filepath.Join(dir, "old.go")); !os.IsNotExist(err) {
+		t.Errorf("removed file is read with %v", err)
+	}
+}
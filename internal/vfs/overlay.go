@@ -0,0 +1,145 @@
+package vfs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+type ChangeKind string
+
+const (
+	Created  ChangeKind = "created"
+	Modified ChangeKind = "modified"
+	Removed  ChangeKind = "removed"
+)
+
+// Change is the difference between file on disk and in the overlay.
+type Change struct {
+	Path   string
+	Kind   ChangeKind
+	Before []byte
+	After  []byte
+}
+
+type file struct {
+	data    []byte
+	removed bool
+}
+
+// Overlay keeps all writes in memory on top of the files on disk,
+// so nothing is changed until the caller decides what to do with Changes.
+type Overlay struct {
+	files map[string]*file
+}
+
+func NewOverlay() *Overlay {
+	return &Overlay{files: make(map[string]*file)}
+}
+
+func (o *Overlay) ReadFile(path string) ([]byte, error) {
+	f, ok := o.files[filepath.Clean(path)]
+	if !ok {
+		return os.ReadFile(path)
+	}
+
+	if f.removed {
+		return nil, &fs.PathError{Op: "open", Path: path, Err: fs.ErrNotExist}
+	}
+
+	return bytes.Clone(f.data), nil
+}
+
+func (o *Overlay) WriteFile(path string, data []byte) error {
+	o.files[filepath.Clean(path)] = &file{data: bytes.Clone(data)}
+	return nil
+}
+
+func (o *Overlay) Remove(path string) error {
+	if _, err := o.ReadFile(path); err != nil {
+		return err
+	}
+
+	o.files[filepath.Clean(path)] = &file{removed: true}
+	return nil
+}
+
+// Changes returns files which differ from the disk sorted by path.
+func (o *Overlay) Changes() ([]Change, error) {
+	changes := make([]Change, 0, len(o.files))
+	for path, f := range o.files {
+		before, err := os.ReadFile(path)
+		exists := err == nil
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+
+		change := Change{Path: path, Before: before, After: f.data}
+		switch {
+		default:
+			continue
+		case f.removed && exists:
+			change.Kind = Removed
+		case !f.removed && !exists:
+			change.Kind = Created
+		case !f.removed && !bytes.Equal(before, f.data):
+			change.Kind = Modified
+		}
+
+		changes = append(changes, change)
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+
+	return changes, nil
+}
+
+// Diff writes unified diff of every change and the summary of touched files.
+// Paths are printed relative to base.
+func (o *Overlay) Diff(w io.Writer, base string) error {
+	changes, err := o.Changes()
+	if err != nil {
+		return err
+	}
+
+	for _, c := range changes {
+		name := relPath(base, c.Path)
+		from, to := "a/"+name, "b/"+name
+		switch c.Kind {
+		case Created:
+			from = "/dev/null"
+		case Removed:
+			to = "/dev/null"
+		}
+
+		if _, err := io.WriteString(w, Unified(from, to, string(c.Before), string(c.After))); err != nil {
+			return err
+		}
+	}
+
+	if _, err := fmt.Fprintf(w, "%d file(s) touched\n", len(changes)); err != nil {
+		return err
+	}
+
+	for _, c := range changes {
+		if _, err := fmt.Fprintf(w, "\t%-8s %s\n", c.Kind, relPath(base, c.Path)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func relPath(base, path string) string {
+	rel, err := filepath.Rel(base, path)
+	if err != nil {
+		return path
+	}
+
+	return filepath.ToSlash(rel)
+}
@@ -1,37 +1,83 @@
 package main
 
 import (
-	"github.com/Bionic2113/errgen/internal/prcs"
-	"github.com/Bionic2113/errgen/pkg/skipper"
-	"github.com/Bionic2113/errgen/pkg/stringer"
-	"github.com/ilyakaznacheev/cleanenv"
+	"fmt"
+	"os"
+	"runtime/debug"
 )
 
-type Config struct {
-	Skipper           skipper.Config  `yaml:"skipper"`
-	Stringer          stringer.Config `yaml:"stringer"`
-	WrapperFilename   string          `yaml:"wrapper_filename"`
-	SimpleErrFilename string          `yaml:"simple_err_filename"`
-}
+// version is set with -ldflags "-X main.version=..." on release builds.
+var version = "dev"
+
+const usage = `errgen - error wrapper generator for Go
+
+Usage:
+	errgen <command> [flags] [packages]
+
+Commands:
+	generate    rewrite error returns and generate wrappers (default)
+	check       fail if there are unwrapped returns or stale generated files
+	clean       remove all errgen wrapping and generated files
+	version     print errgen version
+	help        print this help
+
+Packages are patterns relative to --dir: "./..." (default) processes the
+whole tree, "./internal/..." a sub-tree and "./internal" a single directory.
+Without the command packages are generated: errgen internal/... or errgen internal
+
+Run "errgen <command> -h" to see the command flags.
+`
 
 func main() {
-	cfg := &Config{}
-	if err := cleanenv.ReadConfig(".errgen.yaml", cfg); err != nil {
-		panic("[WARN] Not found config file (.errgen.yaml): " + err.Error())
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	cmd := "generate"
+	if len(args) > 0 && isCommand(args[0]) {
+		cmd, args = args[0], args[1:]
 	}
 
-	processor, err := prcs.New(
-		cfg.SimpleErrFilename, cfg.WrapperFilename,
-		stringer.NewStringer(cfg.Stringer),
-		skipper.New(cfg.Skipper),
-	)
+	switch cmd {
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", cmd, usage)
+		return 2
+	case "help", "-h", "-help", "--help":
+		fmt.Print(usage)
+		return 0
+	case "version":
+		fmt.Println("errgen", buildVersion())
+		return 0
+	case "generate":
+		return generate(args)
+	case "check":
+		return check(args)
+	case "clean":
+		return clean(args)
+	}
+}
 
-	if err != nil {
-		panic(err)
+// isCommand reports whether the first argument is the command and not
+// the flag or the package pattern of generate. Commands take precedence
+// over directories with the same names.
+func isCommand(arg string) bool {
+	switch arg {
+	case "help", "-h", "-help", "--help", "version", "generate", "check", "clean":
+		return true
 	}
-	if err := processor.ProcessFiles(); err != nil {
-		panic(err)
+
+	return arg != "" && arg[0] != '-' && !isPattern(arg)
+}
+
+func buildVersion() string {
+	if version != "dev" {
+		return version
+	}
+
+	info, ok := debug.ReadBuildInfo()
+	if !ok || info.Main.Version == "" || info.Main.Version == "(devel)" {
+		return version
 	}
 
-	processor.GenerateErrorFiles()
+	return info.Main.Version
 }
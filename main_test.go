@@ -0,0 +1,45 @@
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+func TestIsCommand(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	for _, name := range []string{"internal", "check"} {
+		if err := os.Mkdir(name, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		arg  string
+		want bool
+	}{
+		{"check", true},
+		{"version", true},
+		{"-h", true},
+		{"--help", true},
+		{"--dir", false},
+		{"./...", false},
+		{"internal/...", false},
+		{"pkg/user", false},
+		{"internal", false},
+		{"unknown", true},
+	}
+
+	for _, tt := range tests {
+		if got := isCommand(tt.arg); got != tt.want {
+			t.Errorf("isCommand(%q) = %v, want %v", tt.arg, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+// Package errgen is the runtime part of errgen. Generated wrappers
+// implement Wrapped without importing this package, so it is needed
+// only to inspect errors:
+//
+//	var w errgen.Wrapped
+//	if errors.As(err, &w) {
+//		log.Println(w.Func(), w.Reason(), w.Args())
+//	}
+package errgen
+
+import "errors"
+
+// Wrapped is implemented by every generated wrapper.
+type Wrapped interface {
+	error
+	Unwrap() error
+
+	// Reason is the failed call or the reason of the error, e.g. "db.Exec".
+	Reason() string
+	// Func is the wrapped function: "Save" or "User.Save" for methods.
+	Func() string
+	// Package is the import path of the function package.
+	Package() string
+	// Args are the function arguments by their names.
+	Args() map[string]any
+}
+
+// Located is implemented by wrappers generated with generator.location.
+type Located interface {
+	// Location is file:line of the return site relative to the generation root.
+	Location() string
+}
+
+// Stacked is implemented by wrappers generated with generator.stack.
+type Stacked interface {
+	// Stack is the program counters of the constructor callers,
+	// use runtime.CallersFrames to get functions and lines.
+	Stack() []uintptr
+}
+
+// Chain returns all wrappers from the err tree, the outermost first.
+// Errors joined by errors.Join are visited in their order.
+func Chain(err error) []Wrapped {
+	var chain []Wrapped
+	walk(err, func(w Wrapped) { chain = append(chain, w) })
+
+	return chain
+}
+
+func walk(err error, fn func(w Wrapped)) {
+	if err == nil {
+		return
+	}
+
+	if w, ok := err.(Wrapped); ok {
+		fn(w)
+	}
+
+	switch v := err.(type) {
+	case interface{ Unwrap() error }:
+		walk(v.Unwrap(), fn)
+	case interface{ Unwrap() []error }:
+		for _, e := range v.Unwrap() {
+			walk(e, fn)
+		}
+	}
+}
+
+// As finds the first wrapper in the err tree, it is the shortcut for errors.As.
+func As(err error) (Wrapped, bool) {
+	var w Wrapped
+	ok := errors.As(err, &w)
+
+	return w, ok
+}
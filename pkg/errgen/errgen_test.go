@@ -0,0 +1,61 @@
+package errgen
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+)
+
+// saveError implements Wrapped like generated wrappers.
+type saveError struct {
+	id     int
+	reason string
+	err    error
+}
+
+func (e *saveError) Error() string {
+	return "Save - " + e.reason + "\n" + e.err.Error()
+}
+
+func (e *saveError) Unwrap() error        { return e.err }
+func (e *saveError) Reason() string       { return e.reason }
+func (e *saveError) Func() string         { return "Save" }
+func (e *saveError) Package() string      { return "example.com/user" }
+func (e *saveError) Args() map[string]any { return map[string]any{"id": e.id} }
+
+func TestChain(t *testing.T) {
+	err := fmt.Errorf("handler: %w", errors.Join(
+		&saveError{id: 1, reason: "db.Exec", err: io.EOF},
+		&saveError{id: 2, reason: "db.Query", err: fmt.Errorf("retry: %w", &saveError{id: 3, reason: "db.Ping", err: io.EOF})},
+	))
+
+	var ids []int
+	for _, w := range Chain(err) {
+		ids = append(ids, w.Args()["id"].(int))
+	}
+	if fmt.Sprint(ids) != "[1 2 3]" {
+		t.Errorf("Chain returns wrappers %v, want [1 2 3]", ids)
+	}
+
+	if chain := Chain(io.EOF); len(chain) != 0 {
+		t.Errorf("Chain(io.EOF) = %v, want nothing", chain)
+	}
+	if chain := Chain(nil); len(chain) != 0 {
+		t.Errorf("Chain(nil) = %v, want nothing", chain)
+	}
+}
+
+func TestAs(t *testing.T) {
+	inner := &saveError{id: 2, reason: "db.Ping", err: io.EOF}
+	outer := &saveError{id: 1, reason: "db.Exec", err: inner}
+
+	w, ok := As(fmt.Errorf("handler: %w", outer))
+	if !ok || w != outer {
+		t.Errorf("As returns %v, %v, want the outer wrapper", w, ok)
+	}
+
+	if w, ok := As(io.EOF); ok || w != nil {
+		t.Errorf("As(io.EOF) = %v, %v, want nil, false", w, ok)
+	}
+}
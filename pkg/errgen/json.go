@@ -0,0 +1,98 @@
+package errgen
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// MarshalChain encodes the whole err tree: every wrapper is the object
+// with its context and the cause, other errors are objects with the message
+// and, when they wrap something, the cause or causes of errors.Join.
+//
+//	{"package": "...", "function": "Save", "reason": "db.Exec",
+//	 "args": {"id": 7}, "cause": {"message": "connection refused"}}
+//
+// Arguments are encoded like in generated MarshalJSON: values with String()
+// or Error() are encoded as their text, so fields hidden by stringer
+// are not exposed.
+func MarshalChain(err error) ([]byte, error) {
+	return json.Marshal(document(err))
+}
+
+func document(err error) any {
+	if err == nil {
+		return nil
+	}
+
+	doc := make(map[string]any)
+	if w, ok := err.(Wrapped); ok {
+		wrapperFields(w, doc)
+		if cause := w.Unwrap(); cause != nil {
+			doc["cause"] = document(cause)
+		}
+		return doc
+	}
+
+	doc["message"] = err.Error()
+	switch v := err.(type) {
+	case interface{ Unwrap() error }:
+		if cause := v.Unwrap(); cause != nil {
+			doc["cause"] = document(cause)
+		}
+	case interface{ Unwrap() []error }:
+		causes := make([]any, 0, len(v.Unwrap()))
+		for _, e := range v.Unwrap() {
+			causes = append(causes, document(e))
+		}
+		doc["causes"] = causes
+	}
+
+	return doc
+}
+
+// wrapperFields sets fields of the wrapper itself without the cause.
+// Generated MarshalJSON is used when it exists, so custom templates are respected.
+func wrapperFields(w Wrapped, doc map[string]any) {
+	if m, ok := w.(json.Marshaler); ok {
+		var fields map[string]json.RawMessage
+		if data, err := m.MarshalJSON(); err == nil && json.Unmarshal(data, &fields) == nil {
+			for key, value := range fields {
+				if key != "cause" {
+					doc[key] = value
+				}
+			}
+			return
+		}
+	}
+
+	doc["package"] = w.Package()
+	doc["function"] = w.Func()
+	doc["reason"] = w.Reason()
+	if l, ok := w.(Located); ok {
+		doc["location"] = l.Location()
+	}
+
+	if args := w.Args(); len(args) > 0 {
+		encoded := make(map[string]json.RawMessage, len(args))
+		for name, v := range args {
+			encoded[name] = marshalArg(v)
+		}
+		doc["args"] = encoded
+	}
+}
+
+// marshalArg is the same as marshalArgErrGen of generated files.
+func marshalArg(v any) json.RawMessage {
+	switch v.(type) {
+	case json.Marshaler:
+	case fmt.Stringer, error:
+		v = fmt.Sprint(v)
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		data, _ = json.Marshal(fmt.Sprintf("%#v", v))
+	}
+
+	return data
+}
@@ -0,0 +1,75 @@
+package errgen
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// user hides its fields from errors like the generated String().
+type user struct {
+	Password string
+}
+
+func (u user) String() string { return "user" }
+
+// marshaledError is the wrapper generated with generator.json.
+type marshaledError struct {
+	saveError
+}
+
+func (e *marshaledError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{"function": e.Func(), "reason": e.reason, "cause": "ignored"})
+}
+
+func TestMarshalChain(t *testing.T) {
+	err := fmt.Errorf("handler: %w", errors.Join(
+		&saveError{id: 1, reason: "db.Exec", err: io.EOF},
+		&marshaledError{saveError{id: 2, reason: "db.Query", err: io.ErrUnexpectedEOF}},
+	))
+
+	data, e := MarshalChain(err)
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	want := `{"cause":{"causes":[` +
+		`{"args":{"id":1},"cause":{"message":"EOF"},"function":"Save","package":"example.com/user","reason":"db.Exec"},` +
+		`{"cause":{"message":"unexpected EOF"},"function":"Save","reason":"db.Query"}],` +
+		`"message":"Save - db.Exec\nEOF\nSave - db.Query\nunexpected EOF"},` +
+		`"message":"handler: Save - db.Exec\nEOF\nSave - db.Query\nunexpected EOF"}`
+	if string(data) != want {
+		t.Errorf("MarshalChain:\n%s\nwant:\n%s", data, want)
+	}
+
+	if data, _ := MarshalChain(nil); string(data) != "null" {
+		t.Errorf("MarshalChain(nil) = %s, want null", data)
+	}
+}
+
+func TestMarshalArg(t *testing.T) {
+	tests := []struct {
+		v    any
+		want string
+	}{
+		{7, `7`},
+		{"name", `"name"`},
+		{user{Password: "secret"}, `"user"`},
+		{io.EOF, `"EOF"`},
+		{json.RawMessage(`{"a":1}`), `{"a":1}`},
+	}
+
+	for _, tt := range tests {
+		if got := string(marshalArg(tt.v)); got != tt.want {
+			t.Errorf("marshalArg(%#v) = %s, want %s", tt.v, got, tt.want)
+		}
+	}
+
+	// Values which can not be encoded are printed with %#v
+	if got := string(marshalArg(make(chan int))); !strings.HasPrefix(got, `"(chan int)(0x`) {
+		t.Errorf("marshalArg(chan) = %s, want the printed pointer", got)
+	}
+}
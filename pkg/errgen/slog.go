@@ -0,0 +1,134 @@
+package errgen
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"slices"
+	"strconv"
+)
+
+// LogValue returns err as nested groups: every wrapper is the group with its
+// context and the cause, so the whole Unwrap chain is queryable in logs.
+// Errors without wrappers inside are logged as their messages.
+func LogValue(err error) slog.Value {
+	if err == nil {
+		return slog.StringValue("<nil>")
+	}
+
+	if w, ok := err.(Wrapped); ok {
+		return slog.GroupValue(append(wrapperAttrs(w), slog.Attr{Key: "cause", Value: LogValue(w.Unwrap())})...)
+	}
+
+	if len(Chain(err)) == 0 {
+		return slog.StringValue(err.Error())
+	}
+
+	attrs := []slog.Attr{slog.String("message", err.Error())}
+	switch v := err.(type) {
+	case interface{ Unwrap() error }:
+		attrs = append(attrs, slog.Attr{Key: "cause", Value: LogValue(v.Unwrap())})
+	case interface{ Unwrap() []error }:
+		causes := make([]slog.Attr, 0, len(v.Unwrap()))
+		for i, e := range v.Unwrap() {
+			causes = append(causes, slog.Attr{Key: strconv.Itoa(i), Value: LogValue(e)})
+		}
+		attrs = append(attrs, slog.Attr{Key: "causes", Value: slog.GroupValue(causes...)})
+	}
+
+	return slog.GroupValue(attrs...)
+}
+
+// Attr returns err as the attribute with LogValue.
+func Attr(key string, err error) slog.Attr {
+	return slog.Attr{Key: key, Value: LogValue(err)}
+}
+
+// wrapperAttrs returns attributes of the wrapper itself without the cause.
+// Generated LogValue is used when it exists, so custom templates are respected.
+func wrapperAttrs(w Wrapped) []slog.Attr {
+	if lv, ok := w.(slog.LogValuer); ok {
+		if v := lv.LogValue(); v.Kind() == slog.KindGroup {
+			attrs := make([]slog.Attr, 0, len(v.Group()))
+			for _, a := range v.Group() {
+				if a.Key != "cause" {
+					attrs = append(attrs, a)
+				}
+			}
+			return attrs
+		}
+	}
+
+	attrs := []slog.Attr{
+		slog.String("package", w.Package()),
+		slog.String("function", w.Func()),
+		slog.String("reason", w.Reason()),
+	}
+	if l, ok := w.(Located); ok {
+		attrs = append(attrs, slog.String("location", l.Location()))
+	}
+
+	if args := w.Args(); len(args) > 0 {
+		group := make([]any, 0, len(args))
+		for _, name := range slices.Sorted(maps.Keys(args)) {
+			group = append(group, slog.Any(name, args[name]))
+		}
+		attrs = append(attrs, slog.Group("args", group...))
+	}
+
+	return attrs
+}
+
+// Handler logs errors of records and their attributes with LogValue.
+type Handler struct {
+	slog.Handler
+}
+
+// NewHandler wraps h, so every error attribute is logged as the chain of groups:
+//
+//	logger := slog.New(errgen.NewHandler(slog.NewJSONHandler(os.Stdout, nil)))
+//	logger.Error("save failed", "err", err)
+func NewHandler(h slog.Handler) *Handler {
+	return &Handler{Handler: h}
+}
+
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	nr := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		nr.AddAttrs(replaceErrors(a))
+		return true
+	})
+
+	return h.Handler.Handle(ctx, nr)
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	replaced := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		replaced[i] = replaceErrors(a)
+	}
+
+	return &Handler{Handler: h.Handler.WithAttrs(replaced)}
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{Handler: h.Handler.WithGroup(name)}
+}
+
+func replaceErrors(a slog.Attr) slog.Attr {
+	switch a.Value.Kind() {
+	case slog.KindAny, slog.KindLogValuer:
+		if err, ok := a.Value.Any().(error); ok {
+			return Attr(a.Key, err)
+		}
+	case slog.KindGroup:
+		group := a.Value.Group()
+		replaced := make([]slog.Attr, len(group))
+		for i, ga := range group {
+			replaced[i] = replaceErrors(ga)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(replaced...)}
+	}
+
+	return a
+}
@@ -0,0 +1,81 @@
+package errgen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// loggedError is the wrapper generated with generator.slog.
+type loggedError struct {
+	saveError
+}
+
+func (e *loggedError) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("function", e.Func()),
+		slog.String("reason", e.reason),
+		slog.Any("cause", e.err),
+	)
+}
+
+// logJSON logs err by the JSON handler wrapped with NewHandler and returns the record without time.
+func logJSON(t *testing.T, log func(l *slog.Logger)) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	h := slog.NewJSONHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+	log(slog.New(NewHandler(h)))
+
+	return strings.TrimSpace(buf.String())
+}
+
+func TestHandler(t *testing.T) {
+	err := fmt.Errorf("handler: %w", errors.Join(
+		&saveError{id: 1, reason: "db.Exec", err: io.EOF},
+		&loggedError{saveError{id: 2, reason: "db.Query", err: io.ErrUnexpectedEOF}},
+	))
+
+	got := logJSON(t, func(l *slog.Logger) { l.Error("failed", "err", err) })
+	want := `{"level":"ERROR","msg":"failed","err":{"message":"handler: Save - db.Exec\nEOF\nSave - db.Query\nunexpected EOF",` +
+		`"cause":{"message":"Save - db.Exec\nEOF\nSave - db.Query\nunexpected EOF","causes":{` +
+		`"0":{"package":"example.com/user","function":"Save","reason":"db.Exec","args":{"id":1},"cause":"EOF"},` +
+		`"1":{"function":"Save","reason":"db.Query","cause":"unexpected EOF"}}}}}`
+	if got != want {
+		t.Errorf("logged:\n%s\nwant:\n%s", got, want)
+	}
+
+	got = logJSON(t, func(l *slog.Logger) {
+		l.With("err", &saveError{id: 3, reason: "db.Ping", err: io.EOF}).WithGroup("g").Info("retry", "plain", io.EOF)
+	})
+	want = `{"level":"INFO","msg":"retry","err":{"package":"example.com/user","function":"Save","reason":"db.Ping","args":{"id":3},"cause":"EOF"},"g":{"plain":"EOF"}}`
+	if got != want {
+		t.Errorf("logged:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestLogValue(t *testing.T) {
+	if v := LogValue(nil); v.String() != "<nil>" {
+		t.Errorf("LogValue(nil) = %v", v)
+	}
+
+	if v := LogValue(fmt.Errorf("read: %w", io.EOF)); v.Kind() != slog.KindString || v.String() != "read: EOF" {
+		t.Errorf("LogValue of the error without wrappers = %v, want its message", v)
+	}
+
+	attr := Attr("err", &saveError{id: 1, reason: "db.Exec", err: io.EOF})
+	if attr.Key != "err" || attr.Value.Kind() != slog.KindGroup {
+		t.Errorf("Attr = %v, want the group", attr)
+	}
+}
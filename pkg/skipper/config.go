@@ -1,36 +1,73 @@
 package skipper
 
 import (
+	"fmt"
 	"log/slog"
 	"os"
+	"regexp"
+
+	"github.com/Bionic2113/errgen/pkg/utils"
 )
 
-// TODO(bionic2113): Add
-// 1) Ability to change Is, As and other functions
 type Config struct {
 	SkipTypes   map[string]pkgInfo `yaml:"skip_types"`
 	WithDefault bool               `yaml:"with_default" env-default:"true"`
 	Rules       []Rule             `yaml:"rules"`
+	Redact      Redact             `yaml:"redact"`
+}
+
+// Redact describes arguments which are kept in wrappers, but hidden
+// in their output. Types are redacted with redact flag in skip_types.
+type Redact struct {
+	// Names are regular expressions of argument names, e.g. "(?i)password|token".
+	Names []string `yaml:"names"`
+	// Mode is mask ([REDACTED]) or hash (short sha256 of the value).
+	Mode utils.Redaction `yaml:"mode" env-default:"mask"`
+	// TagName is the struct tag with the redact option, it is the stringer tag.
+	TagName string `yaml:"-"`
 }
 
 type Skipper struct {
 	Config
 	workDir string
 	module  string
+	names   []*regexp.Regexp
 	l       *slog.Logger
 }
 
-func New(cfg Config) *Skipper {
+// New creates skipper for sources in workDir.
+func New(cfg Config, workDir string) (*Skipper, error) {
 	sk := &Skipper{
-		Config: cfg,
-		l:      slog.New(slog.NewJSONHandler(os.Stdout, nil)).WithGroup("Skipper"),
+		Config:  cfg,
+		workDir: workDir,
+		l:       slog.New(slog.NewJSONHandler(os.Stderr, nil)).WithGroup("Skipper"),
+	}
+
+	switch sk.Redact.Mode {
+	case "":
+		sk.Redact.Mode = utils.RedactMask
+	case utils.RedactMask, utils.RedactHash:
+	default:
+		return nil, fmt.Errorf("unknown redact mode %q, expected %s or %s", sk.Redact.Mode, utils.RedactMask, utils.RedactHash)
 	}
 
-	sk.workDirAndModule()
+	for _, name := range sk.Redact.Names {
+		re, err := regexp.Compile(name)
+		if err != nil {
+			return nil, fmt.Errorf("redact name: %w", err)
+		}
+		sk.names = append(sk.names, re)
+	}
+
+	sk.loadModule()
 
 	if !sk.WithDefault {
 		sk.l.Info("Without default preset")
-		return sk
+		return sk, nil
+	}
+
+	if sk.SkipTypes == nil {
+		sk.SkipTypes = make(map[string]pkgInfo, len(defaultSkipTypes))
 	}
 
 	for k, v := range defaultSkipTypes {
@@ -46,5 +83,5 @@ func New(cfg Config) *Skipper {
 
 	sk.Rules = append(sk.Rules, defaultRules...)
 
-	return sk
+	return sk, nil
 }
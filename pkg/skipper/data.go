@@ -19,6 +19,8 @@ var defaultRules = []Rule{
 type pkgInfo struct {
 	All   bool     `yaml:"all"`
 	Names []string `yaml:"names"`
+	// Redact keeps arguments of the types, but hides them in the output.
+	Redact bool `yaml:"redact"`
 }
 
 type RuleType string
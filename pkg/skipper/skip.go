@@ -4,21 +4,43 @@ import (
 	"bufio"
 	"log/slog"
 	"os"
+	"path/filepath"
 	"slices"
 	"strings"
+
+	"github.com/Bionic2113/errgen/pkg/utils"
 )
 
 func (s *Skipper) NeedSkipField(name, path string) bool {
 	info, ok := s.SkipTypes[path]
-	if !ok {
+	if !ok || info.Redact {
 		return false
 	}
 
-	if info.All {
-		return true
+	return info.All || slices.Contains(info.Names, name)
+}
+
+func (s *Skipper) NeedRedactArg(name, typeName, typePath string) bool {
+	for _, re := range s.names {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+
+	info, ok := s.SkipTypes[typePath]
+	if !ok || !info.Redact || typeName == "" {
+		return false
 	}
 
-	return slices.Contains(info.Names, name)
+	return info.All || slices.Contains(info.Names, typeName)
+}
+
+func (s *Skipper) Redaction() utils.Redaction {
+	return s.Redact.Mode
+}
+
+func (s *Skipper) RedactTag() string {
+	return s.Redact.TagName
 }
 
 func (s *Skipper) NeedSkipFile(path string) bool {
@@ -49,17 +71,10 @@ func (s *Skipper) ModuleName(path string) string {
 	return s.module + strings.TrimPrefix(path, s.workDir)
 }
 
-func (s *Skipper) workDirAndModule() {
-	wd, err := os.Getwd()
-	if err != nil {
-		s.l.Error("os.Getwd", slog.String("error", err.Error()))
-		return
-	}
-	s.l.Info("Work directory is loaded", slog.String("WorkDir", wd))
-
-	s.workDir = wd
+func (s *Skipper) loadModule() {
+	s.l.Info("Work directory is loaded", slog.String("WorkDir", s.workDir))
 
-	file, err := os.Open(wd + "/go.mod")
+	file, err := os.Open(filepath.Join(s.workDir, "go.mod"))
 	if err != nil {
 		s.l.Error("os.Open go.mod", slog.String("error", err.Error()))
 		return
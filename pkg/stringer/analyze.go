@@ -1,6 +1,10 @@
 package stringer
 
 import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
 	"strings"
 
 	"github.com/Bionic2113/errgen/pkg/utils"
@@ -34,8 +38,10 @@ const skipValue = "-"
 //	func (s Samuel) String() string {
 //		return "Name" + ": " + s.Name + " " + "Age" + ": " + strconv.Itoa(s.Age)
 //	}
-func (s *Stringer) MakeStringFuncs(pkgInfo utils.PkgInfo, scope *dst.Scope) {
-	for k, v := range scope.Objects {
+func (s *Stringer) MakeStringFuncs(pkgInfo utils.PkgInfo, scope *dst.Scope) error {
+	var errs []error
+	for _, k := range slices.Sorted(maps.Keys(scope.Objects)) {
+		v := scope.Objects[k]
 		if v.Decl == nil {
 			continue
 		}
@@ -45,9 +51,14 @@ func (s *Stringer) MakeStringFuncs(pkgInfo utils.PkgInfo, scope *dst.Scope) {
 			continue
 		}
 
+		owner := ownerName(k, ts)
 		switch t := ts.Type.(type) {
 		case *dst.StructType:
-			structInfo, ok := s.makeStringFunc(k, t)
+			structInfo, ok, err := s.makeStringFunc(owner, t)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
 			if ok {
 				s.structsInfo[pkgInfo] = append(s.structsInfo[pkgInfo], structInfo)
 			}
@@ -62,26 +73,55 @@ func (s *Stringer) MakeStringFuncs(pkgInfo utils.PkgInfo, scope *dst.Scope) {
 				continue
 			}
 
-			structInfo, ok := s.makeStringFunc(k, st)
+			structInfo, ok, err := s.makeStringFunc(owner, st)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
 			if ok {
 				s.structsInfo[pkgInfo] = append(s.structsInfo[pkgInfo], structInfo)
 			}
 		}
 	}
+
+	return errors.Join(errs...)
+}
+
+// ownerName returns the type name with type parameters
+// of the generic type, so String() has the valid receiver: Box[T].
+func ownerName(name string, ts *dst.TypeSpec) string {
+	if ts.TypeParams == nil {
+		return name
+	}
+
+	var params []string
+	for _, field := range ts.TypeParams.List {
+		for _, param := range field.Names {
+			params = append(params, param.Name)
+		}
+	}
+
+	return name + "[" + strings.Join(params, ", ") + "]"
 }
 
-func (s *Stringer) makeStringFunc(name string, st *dst.StructType) (StructInfo, bool) {
+func (s *Stringer) makeStringFunc(name string, st *dst.StructType) (StructInfo, bool, error) {
 	fields := make([]*FieldInfo, 0, len(st.Fields.List))
 	for _, field := range st.Fields.List {
-		tag, ok := s.tagValue(field)
+		tag, redacted, ok := s.tagValue(field)
 		if !ok {
 			continue
 		}
 
+		factName, err := utils.FieldName(field)
+		if err != nil {
+			return StructInfo{}, false, fmt.Errorf("struct %s: %w", name, err)
+		}
+
 		fieldInfo := &FieldInfo{
-			FactName:   utils.FieldName(field),
+			FactName:   factName,
 			CustomName: tag,
 			Type:       "any", // that easier than real type for not basic type
+			Redacted:   redacted,
 		}
 
 		fields = append(fields, fieldInfo)
@@ -97,24 +137,26 @@ func (s *Stringer) makeStringFunc(name string, st *dst.StructType) (StructInfo,
 	}
 
 	if len(fields) == 0 {
-		return StructInfo{}, false
+		return StructInfo{}, false, nil
 	}
 
-	return StructInfo{Name: name, Fields: fields}, true
+	return StructInfo{Name: name, Fields: fields}, true, nil
 }
 
-func (s *Stringer) tagValue(field *dst.Field) (string, bool) {
+// tagValue returns the custom name of the field and whether it is redacted:
+// `errgen:"Name,redact"`. False is returned for skipped fields.
+func (s *Stringer) tagValue(field *dst.Field) (string, bool, bool) {
 	if field.Tag == nil {
-		return "", true
+		return "", false, true
 	}
 
 	tagString := strings.TrimSpace(field.Tag.Value)
 	if len(tagString) == 0 {
-		return "", true
+		return "", false, true
 	}
 
 	if !strings.HasPrefix(tagString, "`") || !strings.HasSuffix(tagString, "`") {
-		return "", true
+		return "", false, true
 	}
 
 	tagString = tagString[1 : len(tagString)-1]
@@ -129,11 +171,12 @@ func (s *Stringer) tagValue(field *dst.Field) (string, bool) {
 		value = value[:len(value)-1]
 
 		if value == skipValue {
-			return "", false
+			return "", false, false
 		}
 
-		return value, true
+		name, opts, _ := strings.Cut(value, ",")
+		return name, slices.Contains(strings.Split(opts, ","), utils.RedactOption), true
 	}
 
-	return "", true
+	return "", false, true
 }
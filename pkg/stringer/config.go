@@ -1,12 +1,19 @@
 package stringer
 
-import "github.com/Bionic2113/errgen/pkg/utils"
+import (
+	"text/template"
+
+	"github.com/Bionic2113/errgen/pkg/utils"
+)
 
 type Config struct {
 	FileName  string `yaml:"filename" env-default:"strings"`
 	TagName   string `yaml:"tagname" env-default:"errgen"`
 	Separator string `yaml:"separator" env-default:"\\n"`
 	Connector string `yaml:"connector" env-default:": "`
+	// Template is the path to text/template file used instead
+	// of the built-in template, see TemplateData.
+	Template string `yaml:"template"`
 }
 
 type Stringer struct {
@@ -15,25 +22,41 @@ type Stringer struct {
 	Separator   string
 	Connector   string
 	structsInfo map[utils.PkgInfo][]StructInfo
+	tmpl        *template.Template
 }
 
-func NewStringer(cfg Config) *Stringer {
+func NewStringer(cfg Config) (*Stringer, error) {
+	tmpl, err := LoadTemplate(cfg.Template)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Stringer{
 		FileName:    cfg.FileName,
 		TagName:     cfg.TagName,
 		Separator:   cfg.Separator,
 		Connector:   cfg.Connector,
 		structsInfo: map[utils.PkgInfo][]StructInfo{},
-	}
+		tmpl:        tmpl,
+	}, nil
 }
 
+// StructInfo is the structure which gets String().
 type StructInfo struct {
 	Name   string
 	Fields []*FieldInfo
 }
 
+// FieldInfo is the printed field of the structure.
 type FieldInfo struct {
-	FactName   string
-	Type       string
+	// FactName is the field name in the code.
+	FactName string
+	// Type is the field type when it is the basic type (int, string, ...),
+	// other types are any.
+	Type string
+	// CustomName is the name from the struct tag, empty when there is no tag.
 	CustomName string
+	// Redacted fields are printed as [REDACTED], they have the redact
+	// tag option: `errgen:",redact"`.
+	Redacted bool
 }
@@ -1,106 +1,94 @@
 package stringer
 
 import (
-	"bytes"
-	"go/parser"
-	"go/printer"
-	"go/token"
-	"os"
+	_ "embed"
+	"errors"
 	"path/filepath"
+	"slices"
 	"strings"
 	"text/template"
 
+	"github.com/Bionic2113/errgen/internal/report"
 	"github.com/Bionic2113/errgen/pkg/utils"
 )
 
-const tmplt = `// Code generated by stringer. DO NOT EDIT.
-package {{.Package}}
-import "fmt"
+//go:embed stringer.go.tmpl
+var defaultTemplate string
 
-{{range .FuncsInfo}}
-func (o {{.Owner}}) String() string {
-	return fmt.Sprintf("{{.Return}}"{{range .Args}}, o.{{ . }}{{end}})
+const redactedValue = "[REDACTED]"
+
+// TemplateData is the data of the stringer template.
+type TemplateData struct {
+	// Package is the package name.
+	Package string
+	// FuncsInfo are String() functions prepared with the config.
+	FuncsInfo []FuncInfo
+	// Structs are the source structures of FuncsInfo in the same order.
+	Structs []StructInfo
+}
+
+// FuncInfo is the String() function of the structure.
+type FuncInfo struct {
+	// Owner is the structure name.
+	Owner string
+	// Return is the fmt format with all fields.
+	Return string
+	// Args are the field names in the order of Return verbs.
+	Args []string
 }
-{{end}}
-`
 
-func (s *Stringer) GenerateFiles() error {
-	for pkgInfo, structInfos := range s.structsInfo {
-		if err := s.generateFile(pkgInfo, structInfos); err != nil {
-			return err
+// LoadTemplate parses the stringer template from path,
+// the built-in one is used when path is empty.
+func LoadTemplate(path string) (*template.Template, error) {
+	return utils.LoadTemplate("stringer", path, defaultTemplate, nil)
+}
+
+func (s *Stringer) GenerateFiles(fs utils.FileSystem) error {
+	var errs []error
+	for _, pkgInfo := range utils.SortedPackages(s.structsInfo) {
+		if err := s.generateFile(fs, pkgInfo, s.structsInfo[pkgInfo]); err != nil {
+			errs = append(errs, report.File(report.Stringer, filepath.Join(pkgInfo.Path, s.FileName+".go"), err))
 		}
 	}
 
-	return nil
+	return errors.Join(errs...)
 }
 
-type funcInfo struct {
-	Owner  string
-	Return string
-	Args   []string
-}
+func (s *Stringer) generateFile(fs utils.FileSystem, pkgInfo utils.PkgInfo, structInfos []StructInfo) error {
+	// Structures are collected from the scope map, so they are sorted by name
+	slices.SortFunc(structInfos, func(a, b StructInfo) int { return strings.Compare(a.Name, b.Name) })
 
-func (s *Stringer) generateFile(pkgInfo utils.PkgInfo, structInfos []StructInfo) error {
-	funcs := make([]funcInfo, len(structInfos))
+	funcs := make([]FuncInfo, len(structInfos))
 
 	for i, si := range structInfos {
-		parts, args := make([]string, len(si.Fields)), make([]string, len(si.Fields))
+		parts, args := make([]string, len(si.Fields)), make([]string, 0, len(si.Fields))
 		for j, field := range si.Fields {
 			name := field.FactName
 			if field.CustomName != "" {
 				name = field.CustomName
 			}
+
+			if field.Redacted {
+				parts[j] = name + s.Connector + redactedValue
+				continue
+			}
+
 			parts[j] = name + s.Connector + utils.Convert(field.Type)
-			args[j] = field.FactName
+			args = append(args, field.FactName)
 		}
 
-		funcs[i] = funcInfo{
+		funcs[i] = FuncInfo{
 			Owner:  si.Name,
 			Return: strings.Join(parts, s.Separator),
 			Args:   args,
 		}
 	}
-	data := struct {
-		Package   string
-		FuncsInfo []funcInfo
-	}{
-		Package:   pkgInfo.Name,
-		FuncsInfo: funcs,
-	}
-	errFilePath := filepath.Join(pkgInfo.Path, s.FileName+".go")
-
-	f, err := os.Create(errFilePath)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
+	data := TemplateData{Package: pkgInfo.Name, FuncsInfo: funcs, Structs: structInfos}
 
-	t, err := template.New("stringer").Parse(tmplt)
+	_, src, err := utils.RenderGo(s.tmpl, data)
 	if err != nil {
 		return err
 	}
 
-	formattedBuf := &bytes.Buffer{}
-	if err := t.Execute(formattedBuf, data); err != nil {
-		return err
-	}
-
-	cfg := printer.Config{Mode: printer.UseSpaces | printer.TabIndent, Tabwidth: 8}
-
-	fset := token.NewFileSet()
-	astFile, err := parser.ParseFile(fset, "", formattedBuf.String(), parser.ParseComments)
-	if err != nil {
-		return err
-	}
-
-	var buf bytes.Buffer
-	if err := cfg.Fprint(&buf, fset, astFile); err != nil {
-		return err
-	}
-
-	if err := os.WriteFile(errFilePath, buf.Bytes(), 0o644); err != nil {
-		return err
-	}
-
-	return nil
+	return fs.WriteFile(filepath.Join(pkgInfo.Path, s.FileName+".go"), src)
 }
@@ -0,0 +1,25 @@
+package utils
+
+import "strings"
+
+const directivePrefix = "//errgen:"
+
+// Directive finds "//errgen:<name> <value>" in comments of the node decorations
+// and returns its value. Block comments "/*errgen:<name>*/" are accepted too,
+// so the directive can be placed inside the parameter list.
+func Directive(decs []string, name string) (string, bool) {
+	for _, dec := range decs {
+		if strings.HasPrefix(dec, "/*") {
+			dec = "//" + strings.TrimSuffix(strings.TrimPrefix(dec, "/*"), "*/")
+		}
+
+		rest, ok := strings.CutPrefix(strings.TrimSpace(dec), directivePrefix+name)
+		if !ok || (rest != "" && rest[0] != ' ' && rest[0] != '\t') {
+			continue
+		}
+
+		return strings.TrimSpace(rest), true
+	}
+
+	return "", false
+}
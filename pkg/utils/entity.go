@@ -3,10 +3,17 @@ package utils
 type FunctionInfo struct {
 	PackageName    string
 	SubPackageName string
-	FunctionName   string
-	ReceiverType   string
-	Args           []ArgInfo
-	Imports        map[string]Path
+	// PackagePath is the import path of the package.
+	PackagePath  string
+	FunctionName string
+	ReceiverType string
+	// TypeName is the name of the wrapper type, the constructor is New<TypeName>.
+	TypeName string
+	Args     []ArgInfo
+	// Values reports whether the wrapper keeps values of fmt.Errorf
+	// arguments besides Args, see the values field of wrappers.
+	Values  bool
+	Imports map[string]Path
 
 	HasError bool
 }
@@ -14,8 +21,30 @@ type FunctionInfo struct {
 type ArgInfo struct {
 	Name string
 	Type string
+
+	// Imports are packages used by Type, by their names in the wrappers file.
+	Imports map[string]Path
+	// Redact is how the argument is rendered in wrappers output,
+	// the argument itself is kept in the wrapper.
+	Redact Redaction
 }
 
+// Redaction is how the sensitive argument is rendered.
+type Redaction string
+
+const (
+	// RedactNone renders the argument as is.
+	RedactNone Redaction = ""
+	// RedactMask renders the argument as [REDACTED].
+	RedactMask Redaction = "mask"
+	// RedactHash renders the short sha256 of the argument,
+	// so equal values still can be matched in logs.
+	RedactHash Redaction = "hash"
+	// RedactFields renders the argument with its generated String(),
+	// where fields with the redact tag option are masked.
+	RedactFields Redaction = "fields"
+)
+
 type PkgInfo struct {
 	Name string
 	Path string
@@ -0,0 +1,9 @@
+package utils
+
+// FileSystem is used by errgen for all reads of sources
+// and writes of modified and generated files.
+type FileSystem interface {
+	ReadFile(path string) ([]byte, error)
+	WriteFile(path string, data []byte) error
+	Remove(path string) error
+}
@@ -0,0 +1,162 @@
+package utils
+
+import (
+	"go/types"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/dave/dst"
+)
+
+const (
+	redactDirective = "redact"
+	// RedactOption is the struct tag option of fields which are masked
+	// in generated String(), e.g. `errgen:",redact"`.
+	RedactOption = "redact"
+)
+
+// argRedaction returns how the argument is rendered in wrappers.
+// Type name and path are empty when the type is not named, fields reports
+// whether the type may have fields with the redact option.
+func argRedaction(name, typeName, typePath string, fields, marked bool, skipper Skipper) Redaction {
+	if marked || skipper.NeedRedactArg(name, typeName, typePath) {
+		return skipper.Redaction()
+	}
+
+	if fields {
+		return RedactFields
+	}
+
+	return RedactNone
+}
+
+// redactedArgs returns names of arguments marked with the directive
+// in the function doc: "//errgen:redact password token".
+func redactedArgs(funcDecl *dst.FuncDecl) map[string]bool {
+	names := make(map[string]bool)
+	value, _ := Directive(funcDecl.Decs.Start, redactDirective)
+	for _, name := range strings.Fields(value) {
+		names[name] = true
+	}
+
+	return names
+}
+
+// isRedactedField reports whether the parameter has the directive comment:
+//
+//	func Login(
+//		login string,
+//		password string, //errgen:redact
+//	) error
+func isRedactedField(field *dst.Field) bool {
+	for _, decs := range [][]string{field.Decs.Start, field.Decs.Type, field.Decs.End} {
+		if _, ok := Directive(decs, redactDirective); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HasRedactedFields reports whether t is the struct, the pointer to it
+// or the slice of them with fields marked by the redact option of tag.
+func HasRedactedFields(t types.Type, tag string) bool {
+	if tag == "" {
+		return false
+	}
+
+	for elem := t; elem != nil; elem = elemType(t) {
+		t = elem
+	}
+
+	if _, ok := types.Unalias(t).(*types.Named); !ok {
+		return false
+	}
+
+	st, ok := t.Underlying().(*types.Struct)
+	if !ok {
+		return false
+	}
+
+	for i := range st.NumFields() {
+		if isRedactedTag(st.Tag(i), tag) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// elemType returns the element of pointers, slices and arrays, nil for other types.
+func elemType(t types.Type) types.Type {
+	switch v := types.Unalias(t).(type) {
+	case *types.Pointer:
+		return v.Elem()
+	case *types.Slice:
+		return v.Elem()
+	case *types.Array:
+		return v.Elem()
+	}
+
+	return nil
+}
+
+// syntaxRedactedFields is HasRedactedFields when types are unknown.
+// Only structs declared in the file are checked by their tags, types
+// which declarations are not seen have no redacted fields.
+func syntaxRedactedFields(expr dst.Expr, tag string) bool {
+	if tag == "" {
+		return false
+	}
+
+	for {
+		switch v := expr.(type) {
+		case *dst.StarExpr:
+			expr = v.X
+		case *dst.ArrayType:
+			expr = v.Elt
+		case *dst.Ellipsis:
+			expr = v.Elt
+		case *dst.ParenExpr:
+			expr = v.X
+		case *dst.Ident:
+			if v.Obj == nil {
+				return false
+			}
+
+			ts, ok := v.Obj.Decl.(*dst.TypeSpec)
+			if !ok {
+				return false
+			}
+			expr = ts.Type
+		case *dst.StructType:
+			for _, field := range v.Fields.List {
+				if field.Tag == nil {
+					continue
+				}
+
+				value, err := strconv.Unquote(field.Tag.Value)
+				if err == nil && isRedactedTag(value, tag) {
+					return true
+				}
+			}
+
+			return false
+		default:
+			return false
+		}
+	}
+}
+
+// isRedactedTag reports whether the struct tag has the redact option.
+func isRedactedTag(structTag, tag string) bool {
+	value, ok := reflect.StructTag(structTag).Lookup(tag)
+	if !ok {
+		return false
+	}
+
+	_, opts, ok := strings.Cut(value, ",")
+	return ok && slices.Contains(strings.Split(opts, ","), RedactOption)
+}
@@ -0,0 +1,113 @@
+package utils
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator"
+)
+
+const redactSrc = `package a
+
+type Credentials struct {
+	Login    string
+	Password string ` + "`errgen:\",redact\"`" + `
+}
+
+type Named struct {
+	Password string ` + "`errgen:\"Secret,omit,redact\"`" + `
+}
+
+type Plain struct {
+	Name string ` + "`errgen:\"name\"`" + `
+}
+
+type Alias = Credentials
+
+type Defined Credentials
+
+type ID int
+
+func f(
+	c Credentials,
+	p *Credentials,
+	s []*Credentials,
+	n Named,
+	pl Plain,
+	a Alias,
+	d Defined,
+	id ID,
+	str string,
+	m map[string]Credentials,
+	inline struct{ Key string ` + "`errgen:\",redact\"`" + ` },
+	ext other.Type,
+	status Status,
+) {}
+`
+
+func TestHasRedactedFields(t *testing.T) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "a.go", redactSrc, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// other.Type is not resolved, it is not checked by the test
+	conf := types.Config{Importer: importer.Default(), Error: func(error) {}}
+	info := &types.Info{Defs: make(map[*ast.Ident]types.Object)}
+	_, _ = conf.Check("a", fset, []*ast.File{file}, info)
+
+	want := map[string]bool{
+		"c": true, "p": true, "s": true, "n": true, "pl": false, "a": true,
+		"d": true, "id": false, "str": false, "m": false, "inline": false,
+	}
+	seen := 0
+	for ident, obj := range info.Defs {
+		redacted, ok := want[ident.Name]
+		if !ok || obj == nil {
+			continue
+		}
+		seen++
+
+		if got := HasRedactedFields(obj.Type(), "errgen"); got != redacted {
+			t.Errorf("HasRedactedFields(%s %s) = %v, want %v", ident.Name, obj.Type(), got, redacted)
+		}
+		if HasRedactedFields(obj.Type(), "") {
+			t.Errorf("HasRedactedFields(%s) without tag = true", ident.Name)
+		}
+	}
+
+	if seen != len(want) {
+		t.Errorf("checked %d parameters, want %d", seen, len(want))
+	}
+}
+
+func TestSyntaxRedactedFields(t *testing.T) {
+	file, err := decorator.Parse(redactSrc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]bool{
+		"c": true, "p": true, "s": true, "n": true, "pl": false, "a": true,
+		"d": true, "id": false, "str": false, "m": false, "inline": true,
+		// Declarations of other files and packages are not seen
+		"ext": false, "status": false,
+	}
+
+	fn := file.Decls[len(file.Decls)-1].(*dst.FuncDecl)
+	for _, field := range fn.Type.Params.List {
+		name := field.Names[0].Name
+		if got := syntaxRedactedFields(field.Type, "errgen"); got != want[name] {
+			t.Errorf("syntaxRedactedFields(%s) = %v, want %v", name, got, want[name])
+		}
+		if syntaxRedactedFields(field.Type, "") {
+			t.Errorf("syntaxRedactedFields(%s) without tag = true", name)
+		}
+	}
+}
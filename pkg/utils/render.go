@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"go/token"
+	"os"
+	"text/template"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator"
+)
+
+// LoadTemplate parses the template file at path,
+// def is used when path is empty.
+func LoadTemplate(name, path, def string, funcs template.FuncMap) (*template.Template, error) {
+	text := def
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read %s template: %w", name, err)
+		}
+		text = string(data)
+	}
+
+	t, err := template.New(name).Funcs(funcs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s template: %w", name, err)
+	}
+
+	return t, nil
+}
+
+// RenderGo executes t and returns the formatted file. Unused imports
+// are removed, so templates may import everything they could need,
+// the rest are sorted. The rendered output must be a valid Go file.
+func RenderGo(t *template.Template, data any) (*dst.File, []byte, error) {
+	var out bytes.Buffer
+	if err := t.Execute(&out, data); err != nil {
+		return nil, nil, fmt.Errorf("execute %s template: %w", t.Name(), err)
+	}
+
+	node, _, err := ParseFile(token.NewFileSet(), t.Name()+" template output", out.Bytes())
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s template output is not valid Go: %w", t.Name(), err)
+	}
+
+	RemoveUnusedImports(node)
+	SortImports(node)
+
+	var buf bytes.Buffer
+	if err := decorator.Fprint(&buf, node); err != nil {
+		return nil, nil, fmt.Errorf("format %s template output: %w", t.Name(), err)
+	}
+
+	return node, buf.Bytes(), nil
+}
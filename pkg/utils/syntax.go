@@ -0,0 +1,230 @@
+package utils
+
+import (
+	"strings"
+
+	"github.com/dave/dst"
+)
+
+// SyntaxTypeString returns the type expression as it is written in the source
+// and imports which are needed for it, it is used when types are unknown.
+// Packages keep their names or aliases from imports of the file. False is returned
+// for expressions which are not types.
+func SyntaxTypeString(expr dst.Expr, imports map[string]Path) (string, map[string]Path, bool) {
+	p := &typePrinter{imports: imports, used: make(map[string]Path)}
+	p.expr(expr)
+	if p.invalid {
+		return "", nil, false
+	}
+
+	return p.buf.String(), p.used, true
+}
+
+type typePrinter struct {
+	buf     strings.Builder
+	imports map[string]Path
+	used    map[string]Path
+	invalid bool
+}
+
+func (p *typePrinter) expr(expr dst.Expr) {
+	switch v := expr.(type) {
+	default:
+		p.invalid = true
+	case *dst.Ident:
+		p.buf.WriteString(v.Name)
+	case *dst.BasicLit:
+		p.buf.WriteString(v.Value)
+	case *dst.SelectorExpr:
+		pkg, ok := v.X.(*dst.Ident)
+		if !ok {
+			p.invalid = true
+			return
+		}
+		if imp, ok := p.imports[pkg.Name]; ok {
+			p.used[pkg.Name] = imp
+		}
+		p.buf.WriteString(pkg.Name + "." + v.Sel.Name)
+	case *dst.ParenExpr:
+		p.buf.WriteString("(")
+		p.expr(v.X)
+		p.buf.WriteString(")")
+	case *dst.StarExpr:
+		p.buf.WriteString("*")
+		p.expr(v.X)
+	case *dst.UnaryExpr:
+		p.buf.WriteString(v.Op.String())
+		p.expr(v.X)
+	case *dst.BinaryExpr:
+		// Constant expressions of array lengths
+		p.expr(v.X)
+		p.buf.WriteString(" " + v.Op.String() + " ")
+		p.expr(v.Y)
+	case *dst.Ellipsis:
+		p.buf.WriteString("...")
+		p.expr(v.Elt)
+	case *dst.ArrayType:
+		p.buf.WriteString("[")
+		if v.Len != nil {
+			p.expr(v.Len)
+		}
+		p.buf.WriteString("]")
+		p.expr(v.Elt)
+	case *dst.MapType:
+		p.buf.WriteString("map[")
+		p.expr(v.Key)
+		p.buf.WriteString("]")
+		p.expr(v.Value)
+	case *dst.ChanType:
+		p.chanType(v)
+	case *dst.FuncType:
+		p.buf.WriteString("func")
+		p.signature(v)
+	case *dst.IndexExpr:
+		p.expr(v.X)
+		p.buf.WriteString("[")
+		p.expr(v.Index)
+		p.buf.WriteString("]")
+	case *dst.IndexListExpr:
+		p.expr(v.X)
+		p.buf.WriteString("[")
+		p.list(v.Indices)
+		p.buf.WriteString("]")
+	case *dst.InterfaceType:
+		p.buf.WriteString("interface{")
+		p.fields(v.Methods, true)
+		p.buf.WriteString("}")
+	case *dst.StructType:
+		p.buf.WriteString("struct{")
+		p.fields(v.Fields, false)
+		p.buf.WriteString("}")
+	}
+}
+
+func (p *typePrinter) chanType(v *dst.ChanType) {
+	switch v.Dir {
+	case dst.SEND:
+		p.buf.WriteString("chan<- ")
+	case dst.RECV:
+		p.buf.WriteString("<-chan ")
+	default:
+		p.buf.WriteString("chan ")
+	}
+
+	// chan (<-chan int) is not the same as chan<- chan int
+	if inner, ok := v.Value.(*dst.ChanType); ok && v.Dir != dst.RECV && inner.Dir == dst.RECV {
+		p.buf.WriteString("(")
+		p.expr(v.Value)
+		p.buf.WriteString(")")
+		return
+	}
+
+	p.expr(v.Value)
+}
+
+// signature writes parameters and results of the function type without names.
+func (p *typePrinter) signature(v *dst.FuncType) {
+	p.buf.WriteString("(")
+	p.list(fieldTypes(v.Params))
+	p.buf.WriteString(")")
+
+	results := fieldTypes(v.Results)
+	switch {
+	case len(results) == 1:
+		p.buf.WriteString(" ")
+		p.expr(results[0])
+	case len(results) > 1:
+		p.buf.WriteString(" (")
+		p.list(results)
+		p.buf.WriteString(")")
+	}
+}
+
+// fields writes fields of the struct or methods of the interface.
+func (p *typePrinter) fields(list *dst.FieldList, methods bool) {
+	if list == nil || len(list.List) == 0 {
+		return
+	}
+
+	p.buf.WriteString(" ")
+	for i, field := range list.List {
+		if i > 0 {
+			p.buf.WriteString("; ")
+		}
+
+		if fn, ok := field.Type.(*dst.FuncType); ok && methods && len(field.Names) > 0 {
+			p.buf.WriteString(field.Names[0].Name)
+			p.signature(fn)
+			continue
+		}
+
+		for j, name := range field.Names {
+			if j > 0 {
+				p.buf.WriteString(", ")
+			}
+			p.buf.WriteString(name.Name)
+		}
+		if len(field.Names) > 0 {
+			p.buf.WriteString(" ")
+		}
+
+		p.expr(field.Type)
+		if field.Tag != nil {
+			p.buf.WriteString(" " + field.Tag.Value)
+		}
+	}
+	p.buf.WriteString(" ")
+}
+
+func (p *typePrinter) list(exprs []dst.Expr) {
+	for i, expr := range exprs {
+		if i > 0 {
+			p.buf.WriteString(", ")
+		}
+		p.expr(expr)
+	}
+}
+
+// fieldTypes returns the type of every name of the list,
+// unnamed fields give one type.
+func fieldTypes(list *dst.FieldList) []dst.Expr {
+	if list == nil {
+		return nil
+	}
+
+	var types []dst.Expr
+	for _, field := range list.List {
+		n := max(len(field.Names), 1)
+		for range n {
+			types = append(types, field.Type)
+		}
+	}
+
+	return types
+}
+
+// syntaxNamedType returns the name and the package name of the named type
+// under pointers, slices and arrays, empty strings are returned for other types.
+func syntaxNamedType(expr dst.Expr) (name, pkg string) {
+	for {
+		switch v := expr.(type) {
+		case *dst.StarExpr:
+			expr = v.X
+		case *dst.ArrayType:
+			expr = v.Elt
+		case *dst.Ellipsis:
+			expr = v.Elt
+		case *dst.ParenExpr:
+			expr = v.X
+		case *dst.Ident:
+			return v.Name, ""
+		case *dst.SelectorExpr:
+			if x, ok := v.X.(*dst.Ident); ok {
+				return v.Sel.Name, x.Name
+			}
+			return "", ""
+		default:
+			return "", ""
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package utils
+
+import (
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator"
+)
+
+// TypeResolver gives type information for nodes of the processed file.
+// It is nil when the package could not be loaded with types,
+// then all checks fall back to the syntax.
+type TypeResolver interface {
+	TypeOf(expr dst.Expr) types.Type
+	ObjectOf(ident *dst.Ident) types.Object
+	Package() *types.Package
+}
+
+type typeResolver struct {
+	dec  *decorator.Decorator
+	info *types.Info
+	pkg  *types.Package
+}
+
+func NewTypeResolver(dec *decorator.Decorator, info *types.Info, pkg *types.Package) TypeResolver {
+	return &typeResolver{dec: dec, info: info, pkg: pkg}
+}
+
+// TypeOf returns nil for unknown nodes and types which were not resolved.
+func (r *typeResolver) TypeOf(expr dst.Expr) types.Type {
+	n, ok := r.dec.Ast.Nodes[expr].(ast.Expr)
+	if !ok {
+		return nil
+	}
+
+	t := r.info.TypeOf(n)
+	if !IsValidType(t) {
+		return nil
+	}
+
+	return t
+}
+
+func (r *typeResolver) ObjectOf(ident *dst.Ident) types.Object {
+	n, ok := r.dec.Ast.Nodes[ident].(*ast.Ident)
+	if !ok {
+		return nil
+	}
+
+	obj := r.info.ObjectOf(n)
+	if obj == nil || !IsValidType(obj.Type()) {
+		return nil
+	}
+
+	return obj
+}
+
+func (r *typeResolver) Package() *types.Package {
+	return r.pkg
+}
+
+// IsValidType reports whether t and all its parts were resolved.
+func IsValidType(t types.Type) bool {
+	return t != nil && !strings.Contains(types.TypeString(t, nil), "invalid type")
+}
+
+// IsErrorType reports whether t can hold any error, so wrapper can be returned
+// as t: error itself, its aliases and types defined as error interface.
+func IsErrorType(t types.Type) bool {
+	return types.Identical(t.Underlying(), types.Universe.Lookup("error").Type().Underlying())
+}
+
+// HasTypeParams reports whether t refers to type parameters anywhere inside.
+func HasTypeParams(t types.Type) bool {
+	switch v := types.Unalias(t).(type) {
+	case *types.TypeParam:
+		return true
+	case *types.Pointer:
+		return HasTypeParams(v.Elem())
+	case *types.Slice:
+		return HasTypeParams(v.Elem())
+	case *types.Array:
+		return HasTypeParams(v.Elem())
+	case *types.Chan:
+		return HasTypeParams(v.Elem())
+	case *types.Map:
+		return HasTypeParams(v.Key()) || HasTypeParams(v.Elem())
+	case *types.Signature:
+		return HasTypeParams(v.Params()) || HasTypeParams(v.Results())
+	case *types.Tuple:
+		for i := range v.Len() {
+			if HasTypeParams(v.At(i).Type()) {
+				return true
+			}
+		}
+	case *types.Struct:
+		for i := range v.NumFields() {
+			if HasTypeParams(v.Field(i).Type()) {
+				return true
+			}
+		}
+	case *types.Named:
+		args := v.TypeArgs()
+		for i := range args.Len() {
+			if HasTypeParams(args.At(i)) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// NamedType returns the named type under pointers, slices and arrays,
+// aliases are resolved.
+func NamedType(t types.Type) *types.Named {
+	switch v := types.Unalias(t).(type) {
+	case *types.Named:
+		return v
+	case *types.Pointer:
+		return NamedType(v.Elem())
+	case *types.Slice:
+		return NamedType(v.Elem())
+	case *types.Array:
+		return NamedType(v.Elem())
+	}
+
+	return nil
+}
+
+// TypeString returns t as it is written in the package of the wrappers
+// and imports which are needed for it. Packages which are imported by the file
+// keep their names or aliases. Types with type parameters become any,
+// because wrappers are not generic.
+func TypeString(t types.Type, pkg *types.Package, imports map[string]Path) (string, map[string]Path) {
+	if HasTypeParams(t) {
+		return "any", nil
+	}
+
+	used := make(map[string]Path)
+	s := types.TypeString(t, func(p *types.Package) string {
+		if pkg != nil && p.Path() == pkg.Path() {
+			return ""
+		}
+
+		for name, imp := range imports {
+			if imp.Path == p.Path() {
+				used[name] = imp
+				return name
+			}
+		}
+
+		used[p.Name()] = Path{Path: p.Path()}
+		return p.Name()
+	})
+
+	return s, used
+}
@@ -3,10 +3,14 @@ package utils
 import (
 	"bytes"
 	"fmt"
+	"go/parser"
 	"go/token"
-	"os"
+	"go/types"
+	"maps"
 	"path/filepath"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/dave/dst"
@@ -21,6 +25,13 @@ var (
 type Skipper interface {
 	NeedSkipField(name, path string) bool
 	ModuleName(path string) string
+	// NeedRedactArg reports whether the argument is kept in the wrapper,
+	// but hidden in its output. Type is empty when it is not named.
+	NeedRedactArg(name, typeName, typePath string) bool
+	// Redaction is how redacted arguments are rendered.
+	Redaction() Redaction
+	// RedactTag is the struct tag with the redact option.
+	RedactTag() string
 }
 
 func SubPackageName(pkgDir, baseDir string) string {
@@ -83,38 +94,124 @@ func CreateFunctionInfo(
 	subPkg string,
 	imports map[string]Path,
 	skipper Skipper,
+	resolver TypeResolver,
 ) FunctionInfo {
-	args := ExtractArgs(funcDecl, pkgInfo.Path, imports, skipper)
+	args := ExtractArgs(funcDecl, pkgInfo.Path, imports, skipper, resolver)
 	receiverType := ExtractReceiverType(funcDecl)
 
-	return FunctionInfo{PackageName: pkgInfo.Name, SubPackageName: subPkg, FunctionName: funcDecl.Name.Name, ReceiverType: receiverType, Args: args, Imports: imports, HasError: true}
+	pkgPath := skipper.ModuleName(pkgInfo.Path)
+	if resolver != nil && resolver.Package() != nil {
+		pkgPath = resolver.Package().Path()
+	}
+
+	return FunctionInfo{PackageName: pkgInfo.Name, SubPackageName: subPkg, PackagePath: pkgPath, FunctionName: funcDecl.Name.Name, ReceiverType: receiverType, Args: args, Imports: imports, HasError: true}
 }
 
+// ExtractReceiverType returns the receiver type name without pointer
+// as it is written, with type parameters of generic types: Repo[T].
 func ExtractReceiverType(funcDecl *dst.FuncDecl) string {
 	if funcDecl.Recv == nil || len(funcDecl.Recv.List) == 0 {
 		return ""
 	}
 
-	if starExpr, ok := funcDecl.Recv.List[0].Type.(*dst.StarExpr); ok {
-		if ident, ok := starExpr.X.(*dst.Ident); ok {
-			return ident.Name
-		}
-	} else if ident, ok := funcDecl.Recv.List[0].Type.(*dst.Ident); ok {
+	expr := funcDecl.Recv.List[0].Type
+	if starExpr, ok := expr.(*dst.StarExpr); ok {
+		expr = starExpr.X
+	}
+
+	var indices []dst.Expr
+	switch v := expr.(type) {
+	case *dst.IndexExpr:
+		expr, indices = v.X, []dst.Expr{v.Index}
+	case *dst.IndexListExpr:
+		expr, indices = v.X, v.Indices
+	}
+
+	ident, ok := expr.(*dst.Ident)
+	if !ok {
+		return ""
+	}
+
+	if len(indices) == 0 {
 		return ident.Name
 	}
 
-	return ""
+	params := make([]string, 0, len(indices))
+	for _, index := range indices {
+		if param, ok := index.(*dst.Ident); ok {
+			params = append(params, param.Name)
+		}
+	}
+
+	return ident.Name + "[" + strings.Join(params, ", ") + "]"
+}
+
+// typeParams returns names of type parameters which are in scope of the function:
+// its own and the parameters of the generic receiver.
+func typeParams(funcDecl *dst.FuncDecl) map[string]bool {
+	params := make(map[string]bool)
+	if funcDecl.Type.TypeParams != nil {
+		for _, field := range funcDecl.Type.TypeParams.List {
+			for _, name := range field.Names {
+				params[name.Name] = true
+			}
+		}
+	}
+
+	if funcDecl.Recv != nil && len(funcDecl.Recv.List) > 0 {
+		dst.Inspect(funcDecl.Recv.List[0].Type, func(n dst.Node) bool {
+			switch v := n.(type) {
+			case *dst.IndexExpr:
+				dst.Inspect(v.Index, func(n dst.Node) bool {
+					if ident, ok := n.(*dst.Ident); ok {
+						params[ident.Name] = true
+					}
+					return true
+				})
+				return false
+			case *dst.IndexListExpr:
+				for _, index := range v.Indices {
+					if ident, ok := index.(*dst.Ident); ok {
+						params[ident.Name] = true
+					}
+				}
+				return false
+			}
+			return true
+		})
+	}
+
+	return params
 }
 
-func WriteModifiedFile(node *dst.File, path string) {
+// usesTypeParams reports whether the type expression refers to any of params.
+func usesTypeParams(expr dst.Expr, params map[string]bool) bool {
+	var found bool
+	dst.Inspect(expr, func(n dst.Node) bool {
+		switch v := n.(type) {
+		case *dst.SelectorExpr:
+			// Sel belongs to other package
+			return false
+		case *dst.Ident:
+			found = found || params[v.Name]
+		}
+		return !found
+	})
+
+	return found
+}
+
+func WriteModifiedFile(fs FileSystem, node *dst.File, path string) error {
 	var buf bytes.Buffer
 	if err := decorator.Fprint(&buf, node); err != nil {
-		fmt.Printf("Error formatting modified file: %v\n", err)
+		return fmt.Errorf("format modified file: %w", err)
 	}
 
-	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
-		fmt.Printf("Error writing modified file: %v\n", err)
+	if err := fs.WriteFile(path, buf.Bytes()); err != nil {
+		return fmt.Errorf("write modified file: %w", err)
 	}
+
+	return nil
 }
 
 func ExtractArgs(
@@ -122,52 +219,112 @@ func ExtractArgs(
 	path string,
 	imports map[string]Path,
 	skipper Skipper,
+	resolver TypeResolver,
 ) []ArgInfo {
 	var args []ArgInfo
 	if funcDecl.Type.Params == nil {
 		return args
 	}
 
+	redacted := redactedArgs(funcDecl)
+	generic := typeParams(funcDecl)
 	for _, field := range funcDecl.Type.Params.List {
-		var typeStr string
-		expr := field.Type
-		if v, ok := expr.(*dst.ArrayType); ok {
-			typeStr = "[]"
-			expr = v.Elt
+		marked := isRedactedField(field)
+		if fieldArgs, ok := typedArgs(field, imports, skipper, resolver); ok {
+			for _, arg := range fieldArgs {
+				fields := HasRedactedFields(arg.typ, skipper.RedactTag())
+				arg.Redact = argRedaction(arg.Name, arg.named, arg.path, fields, marked || redacted[arg.Name], skipper)
+				args = append(args, arg.ArgInfo)
+			}
+			continue
 		}
 
-		if v, ok := expr.(*dst.StarExpr); ok {
-			typeStr += "*"
-			expr = v.X
+		// Wrappers are not generic, so type parameters are erased to any
+		if usesTypeParams(field.Type, generic) {
+			for _, name := range field.Names {
+				if name.Name == "_" {
+					continue
+				}
+				redact := argRedaction(name.Name, "", "", false, marked || redacted[name.Name], skipper)
+				args = append(args, ArgInfo{Name: name.Name, Type: "any", Redact: redact})
+			}
+			continue
 		}
 
-		var isSelector bool
-		if v, ok := expr.(*dst.SelectorExpr); ok {
-			pkg := v.X.(*dst.Ident).Name
-			if skipper.NeedSkipField(v.Sel.Name, imports[pkg].Path) {
-				continue
-			}
-			typeStr += pkg + "."
-			expr = v.Sel
-			isSelector = true
+		typ := field.Type
+		// Variadic parameters are slices inside the function
+		if v, ok := typ.(*dst.Ellipsis); ok {
+			typ = &dst.ArrayType{Elt: v.Elt}
 		}
 
-		if v, ok := expr.(*dst.Ident); ok {
-			if !isSelector && skipper.NeedSkipField(v.Name, skipper.ModuleName(path)) {
-				continue
-			}
-			typeStr += v.Name
-		} else {
-			typeStr += "any"
+		typeStr, typeImports, ok := SyntaxTypeString(typ, imports)
+		if !ok {
+			typeStr, typeImports = "any", map[string]Path{}
 		}
 
+		typeName, pkg := syntaxNamedType(typ)
+		typePath := skipper.ModuleName(path)
+		if pkg != "" {
+			typePath = imports[pkg].Path
+		}
+		if typeName != "" && skipper.NeedSkipField(typeName, typePath) {
+			continue
+		}
+
+		fields := syntaxRedactedFields(typ, skipper.RedactTag())
 		for _, name := range field.Names {
-			args = append(args, ArgInfo{Name: name.Name, Type: typeStr})
+			if name.Name == "_" {
+				continue
+			}
+			redact := argRedaction(name.Name, typeName, typePath, fields, marked || redacted[name.Name], skipper)
+			args = append(args, ArgInfo{Name: name.Name, Type: typeStr, Imports: typeImports, Redact: redact})
 		}
 	}
 	return args
 }
 
+// typedArg is the argument with its resolved type.
+type typedArg struct {
+	ArgInfo
+	typ types.Type
+	// named and path are the name and the package of the named type.
+	named, path string
+}
+
+// typedArgs extracts args of the field with resolved types.
+// It returns false when types are unknown and the syntax should be used.
+func typedArgs(field *dst.Field, imports map[string]Path, skipper Skipper, resolver TypeResolver) ([]typedArg, bool) {
+	if resolver == nil {
+		return nil, false
+	}
+
+	var args []typedArg
+	for _, name := range field.Names {
+		if name.Name == "_" {
+			continue
+		}
+
+		obj := resolver.ObjectOf(name)
+		if obj == nil {
+			return nil, false
+		}
+
+		arg := typedArg{typ: obj.Type()}
+		if named := NamedType(obj.Type()); named != nil && named.Obj().Pkg() != nil {
+			arg.named, arg.path = named.Obj().Name(), named.Obj().Pkg().Path()
+			if skipper.NeedSkipField(arg.named, arg.path) {
+				return nil, true
+			}
+		}
+
+		typeStr, typeImports := TypeString(obj.Type(), resolver.Package(), imports)
+		arg.ArgInfo = ArgInfo{Name: name.Name, Type: typeStr, Imports: typeImports}
+		args = append(args, arg)
+	}
+
+	return args, true
+}
+
 func IsBasicType(typeName string) bool {
 	basicTypes := map[string]bool{
 		"int":     true,
@@ -280,39 +437,136 @@ func RemoveUnusedImports(node *dst.File) {
 
 	// Мало ли у кого-то в нескольких импортах находится,
 	// поэтому снова в цикле проходим и пропускаем пустые
+	// Комментарии удаляемых деклараций (например //go:generate errgen)
+	// переносим на следующую декларацию или на имя пакета
 	var newDecls []dst.Decl
+	var comments dst.Decorations
 	for _, decl := range node.Decls {
-		if genDecl, ok := decl.(*dst.GenDecl); ok && genDecl.Tok == token.IMPORT {
-			if len(genDecl.Specs) > 0 {
-				newDecls = append(newDecls, decl)
+		if genDecl, ok := decl.(*dst.GenDecl); ok && genDecl.Tok == token.IMPORT && len(genDecl.Specs) == 0 {
+			if len(genDecl.Decs.Start) > 0 {
+				comments = append(comments, genDecl.Decs.Start...)
+				comments = append(comments, "\n")
 			}
 			continue
 		}
+		if len(comments) > 0 {
+			decs := decl.Decorations()
+			decs.Start = append(comments, decs.Start...)
+			comments = nil
+		}
 		newDecls = append(newDecls, decl)
 	}
+	if len(comments) > 0 {
+		node.Decs.Name = append(append(node.Decs.Name, "\n"), comments...)
+	}
 
 	node.Decls = newDecls
 }
 
-func FieldName(field *dst.Field) string {
+// SortImports sorts imports of every import declaration by path,
+// like gofmt does for a single group. It is used for generated files,
+// which have no import groups.
+func SortImports(node *dst.File) {
+	for _, decl := range node.Decls {
+		genDecl, ok := decl.(*dst.GenDecl)
+		if !ok || genDecl.Tok != token.IMPORT {
+			continue
+		}
+
+		slices.SortStableFunc(genDecl.Specs, func(a, b dst.Spec) int {
+			return strings.Compare(a.(*dst.ImportSpec).Path.Value, b.(*dst.ImportSpec).Path.Value)
+		})
+	}
+}
+
+// SortedPackages returns packages of m sorted by path,
+// so files are generated and problems are reported in the same order.
+func SortedPackages[V any](m map[PkgInfo]V) []PkgInfo {
+	return slices.SortedFunc(maps.Keys(m), func(a, b PkgInfo) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+}
+
+// AddImport adds import of path to the file if it is not imported yet.
+func AddImport(node *dst.File, path string) {
+	for _, imp := range node.Imports {
+		if imp.Path != nil && strings.Trim(imp.Path.Value, `"`) == path {
+			return
+		}
+	}
+
+	spec := &dst.ImportSpec{Path: &dst.BasicLit{Kind: token.STRING, Value: strconv.Quote(path)}}
+	node.Imports = append(node.Imports, spec)
+
+	for _, decl := range node.Decls {
+		genDecl, ok := decl.(*dst.GenDecl)
+		if !ok || genDecl.Tok != token.IMPORT {
+			continue
+		}
+
+		// Keep the first group of imports sorted
+		index := len(genDecl.Specs)
+		for i, s := range genDecl.Specs {
+			if i > 0 && s.Decorations().Before == dst.EmptyLine {
+				index = i
+				break
+			}
+			if imp, ok := s.(*dst.ImportSpec); ok && strings.Trim(imp.Path.Value, `"`) > path {
+				index = i
+				break
+			}
+		}
+
+		if index < len(genDecl.Specs) && genDecl.Specs[index].Decorations().Before == dst.EmptyLine {
+			spec.Decs.Before = dst.NewLine
+		}
+		genDecl.Specs = slices.Insert(genDecl.Specs, index, dst.Spec(spec))
+		genDecl.Lparen = len(genDecl.Specs) > 1
+
+		return
+	}
+
+	node.Decls = append([]dst.Decl{&dst.GenDecl{Tok: token.IMPORT, Specs: []dst.Spec{spec}}}, node.Decls...)
+}
+
+func FieldName(field *dst.Field) (string, error) {
 	if len(field.Names) != 0 {
-		return field.Names[0].Name
+		return field.Names[0].Name, nil
 	}
 
 	return fieldName(field.Type)
 }
 
-func fieldName(exp dst.Expr) string {
+func fieldName(exp dst.Expr) (string, error) {
 	switch t := exp.(type) {
 	default:
-		panic(fmt.Sprintf("Cann't process Field type: %#v", t))
+		return "", fmt.Errorf("unsupported embedded field type %T", t)
 	case *dst.Ident:
-		return t.Name
+		return t.Name, nil
 	case *dst.SelectorExpr:
-		return t.Sel.Name
+		return t.Sel.Name, nil
 	case *dst.StarExpr:
 		return fieldName(t.X)
+	case *dst.IndexExpr:
+		return fieldName(t.X)
 	case *dst.IndexListExpr:
 		return fieldName(t.X)
 	}
 }
+
+// ParseFile parses and decorates the file. Unlike decorator.ParseFile,
+// the file with syntax errors is not decorated, because it may have broken nodes.
+func ParseFile(fset *token.FileSet, path string, src any) (*dst.File, *decorator.Decorator, error) {
+	f, err := parser.ParseFile(fset, path, src, parser.ParseComments)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dec := decorator.NewDecorator(fset)
+	node, err := dec.DecorateFile(f)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return node, dec, nil
+}
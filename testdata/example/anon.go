@@ -41,10 +41,10 @@ func WithAnon_3() error {
 	func() error {
 		_, err := Marshal()
 		if err != nil {
-			return err
+			return NewWithAnon_3Func1Error("Marshal", err)
 		}
 		if _, err := Marshal(); err != nil {
-			return err
+			return NewWithAnon_3Func1Error("Marshal", err)
 		}
 		return nil
 	}()
@@ -56,3 +56,10 @@ func Marshal() ([]byte, error) {
 	ss := SomeStruct{Name: "Oleg", Age: 164}
 	return json.Marshal(ss)
 }
+
+func WithBlank(_ int, name string, _ ...string) error {
+	if _, err := Marshal(); err != nil {
+		return NewWithBlankError(name, "Marshal", err)
+	}
+	return nil
+}
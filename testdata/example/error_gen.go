@@ -0,0 +1,19 @@
+// Code generated by errgen. DO NOT EDIT.
+package example
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrCountIsOver100       = fmt.Errorf("count is over 100%%")
+	ErrCurrentUserIsNil     = errors.New("current user is nil")
+	ErrCurrentUserIsNil2    = fmt.Errorf("current user is nil")
+	ErrItemNotFound         = errors.New("item not found")
+	ErrNameCannotBeEmpty    = errors.New("name cannot be empty")
+	ErrProcessingFailed     = errors.New("processing failed")
+	ErrRepoIsNotInitialized = errors.New("repo is not initialized")
+	ErrUserIsNil            = errors.New("user is nil")
+	ErrUserIsNil2           = fmt.Errorf("user is nil")
+)
@@ -0,0 +1,967 @@
+// Code generated by errgen. DO NOT EDIT.
+package example
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+type WithAnonError struct {
+	reasonErrGen string
+	errErrGen    error
+}
+
+func NewWithAnonError(reasonErrGen string, errErrGen error) *WithAnonError {
+	return &WithAnonError{
+		reasonErrGen: reasonErrGen,
+		errErrGen:    errErrGen,
+	}
+}
+
+func (e *WithAnonError) Error() string {
+	return "[" + "example" + "] - " +
+		"WithAnon - " + e.reasonErrGen +
+		"\n" +
+		e.errErrGen.Error()
+}
+
+func (e *WithAnonError) Unwrap() error {
+	return e.errErrGen
+}
+
+func (e *WithAnonError) Is(target error) bool {
+	t, ok := target.(*WithAnonError)
+	return ok && t.reasonErrGen == e.reasonErrGen
+}
+
+func (e *WithAnonError) Reason() string {
+	return e.reasonErrGen
+}
+
+func (e *WithAnonError) Func() string {
+	return "WithAnon"
+}
+
+func (e *WithAnonError) Package() string {
+	return "github.com/Bionic2113/errgen/example"
+}
+
+func (e *WithAnonError) Args() map[string]any {
+	return map[string]any{}
+}
+
+type WithAnon_2Error struct {
+	reasonErrGen string
+	errErrGen    error
+}
+
+func NewWithAnon_2Error(reasonErrGen string, errErrGen error) *WithAnon_2Error {
+	return &WithAnon_2Error{
+		reasonErrGen: reasonErrGen,
+		errErrGen:    errErrGen,
+	}
+}
+
+func (e *WithAnon_2Error) Error() string {
+	return "[" + "example" + "] - " +
+		"WithAnon_2 - " + e.reasonErrGen +
+		"\n" +
+		e.errErrGen.Error()
+}
+
+func (e *WithAnon_2Error) Unwrap() error {
+	return e.errErrGen
+}
+
+func (e *WithAnon_2Error) Is(target error) bool {
+	t, ok := target.(*WithAnon_2Error)
+	return ok && t.reasonErrGen == e.reasonErrGen
+}
+
+func (e *WithAnon_2Error) Reason() string {
+	return e.reasonErrGen
+}
+
+func (e *WithAnon_2Error) Func() string {
+	return "WithAnon_2"
+}
+
+func (e *WithAnon_2Error) Package() string {
+	return "github.com/Bionic2113/errgen/example"
+}
+
+func (e *WithAnon_2Error) Args() map[string]any {
+	return map[string]any{}
+}
+
+type WithAnon_3Error struct {
+	reasonErrGen string
+	errErrGen    error
+}
+
+func NewWithAnon_3Error(reasonErrGen string, errErrGen error) *WithAnon_3Error {
+	return &WithAnon_3Error{
+		reasonErrGen: reasonErrGen,
+		errErrGen:    errErrGen,
+	}
+}
+
+func (e *WithAnon_3Error) Error() string {
+	return "[" + "example" + "] - " +
+		"WithAnon_3 - " + e.reasonErrGen +
+		"\n" +
+		e.errErrGen.Error()
+}
+
+func (e *WithAnon_3Error) Unwrap() error {
+	return e.errErrGen
+}
+
+func (e *WithAnon_3Error) Is(target error) bool {
+	t, ok := target.(*WithAnon_3Error)
+	return ok && t.reasonErrGen == e.reasonErrGen
+}
+
+func (e *WithAnon_3Error) Reason() string {
+	return e.reasonErrGen
+}
+
+func (e *WithAnon_3Error) Func() string {
+	return "WithAnon_3"
+}
+
+func (e *WithAnon_3Error) Package() string {
+	return "github.com/Bionic2113/errgen/example"
+}
+
+func (e *WithAnon_3Error) Args() map[string]any {
+	return map[string]any{}
+}
+
+type WithAnon_3Func1Error struct {
+	reasonErrGen string
+	errErrGen    error
+}
+
+func NewWithAnon_3Func1Error(reasonErrGen string, errErrGen error) *WithAnon_3Func1Error {
+	return &WithAnon_3Func1Error{
+		reasonErrGen: reasonErrGen,
+		errErrGen:    errErrGen,
+	}
+}
+
+func (e *WithAnon_3Func1Error) Error() string {
+	return "[" + "example" + "] - " +
+		"WithAnon_3Func1 - " + e.reasonErrGen +
+		"\n" +
+		e.errErrGen.Error()
+}
+
+func (e *WithAnon_3Func1Error) Unwrap() error {
+	return e.errErrGen
+}
+
+func (e *WithAnon_3Func1Error) Is(target error) bool {
+	t, ok := target.(*WithAnon_3Func1Error)
+	return ok && t.reasonErrGen == e.reasonErrGen
+}
+
+func (e *WithAnon_3Func1Error) Reason() string {
+	return e.reasonErrGen
+}
+
+func (e *WithAnon_3Func1Error) Func() string {
+	return "WithAnon_3Func1"
+}
+
+func (e *WithAnon_3Func1Error) Package() string {
+	return "github.com/Bionic2113/errgen/example"
+}
+
+func (e *WithAnon_3Func1Error) Args() map[string]any {
+	return map[string]any{}
+}
+
+type MarshalError struct {
+	reasonErrGen string
+	errErrGen    error
+}
+
+func NewMarshalError(reasonErrGen string, errErrGen error) *MarshalError {
+	return &MarshalError{
+		reasonErrGen: reasonErrGen,
+		errErrGen:    errErrGen,
+	}
+}
+
+func (e *MarshalError) Error() string {
+	return "[" + "example" + "] - " +
+		"Marshal - " + e.reasonErrGen +
+		"\n" +
+		e.errErrGen.Error()
+}
+
+func (e *MarshalError) Unwrap() error {
+	return e.errErrGen
+}
+
+func (e *MarshalError) Is(target error) bool {
+	t, ok := target.(*MarshalError)
+	return ok && t.reasonErrGen == e.reasonErrGen
+}
+
+func (e *MarshalError) Reason() string {
+	return e.reasonErrGen
+}
+
+func (e *MarshalError) Func() string {
+	return "Marshal"
+}
+
+func (e *MarshalError) Package() string {
+	return "github.com/Bionic2113/errgen/example"
+}
+
+func (e *MarshalError) Args() map[string]any {
+	return map[string]any{}
+}
+
+type WithBlankError struct {
+	name         string
+	reasonErrGen string
+	errErrGen    error
+}
+
+func NewWithBlankError(name string, reasonErrGen string, errErrGen error) *WithBlankError {
+	return &WithBlankError{
+		name:         name,
+		reasonErrGen: reasonErrGen,
+		errErrGen:    errErrGen,
+	}
+}
+
+func (e *WithBlankError) Error() string {
+	return "[" + "example" + "] - " +
+		"WithBlank - " + e.reasonErrGen +
+		" - args: {" +
+		"name: " + e.name +
+		"}" + "\n" +
+		e.errErrGen.Error()
+}
+
+func (e *WithBlankError) Unwrap() error {
+	return e.errErrGen
+}
+
+func (e *WithBlankError) Is(target error) bool {
+	t, ok := target.(*WithBlankError)
+	return ok && t.reasonErrGen == e.reasonErrGen
+}
+
+func (e *WithBlankError) Reason() string {
+	return e.reasonErrGen
+}
+
+func (e *WithBlankError) Func() string {
+	return "WithBlank"
+}
+
+func (e *WithBlankError) Package() string {
+	return "github.com/Bionic2113/errgen/example"
+}
+
+func (e *WithBlankError) Args() map[string]any {
+	return map[string]any{
+		"name": e.name,
+	}
+}
+
+type RepoGetError struct {
+	id           int
+	reasonErrGen string
+	errErrGen    error
+}
+
+func NewRepoGetError(id int, reasonErrGen string, errErrGen error) *RepoGetError {
+	return &RepoGetError{
+		id:           id,
+		reasonErrGen: reasonErrGen,
+		errErrGen:    errErrGen,
+	}
+}
+
+func (e *RepoGetError) Error() string {
+	return "[" + "example" + ".Repo[T]" + "] - " +
+		"Get - " + e.reasonErrGen +
+		" - args: {" +
+		"id: " + strconv.Itoa(e.id) +
+		"}" + "\n" +
+		e.errErrGen.Error()
+}
+
+func (e *RepoGetError) Unwrap() error {
+	return e.errErrGen
+}
+
+func (e *RepoGetError) Is(target error) bool {
+	t, ok := target.(*RepoGetError)
+	return ok && t.reasonErrGen == e.reasonErrGen
+}
+
+func (e *RepoGetError) Reason() string {
+	return e.reasonErrGen
+}
+
+func (e *RepoGetError) Func() string {
+	return "Repo[T].Get"
+}
+
+func (e *RepoGetError) Package() string {
+	return "github.com/Bionic2113/errgen/example"
+}
+
+func (e *RepoGetError) Args() map[string]any {
+	return map[string]any{
+		"id": e.id,
+	}
+}
+
+type RepoPutError struct {
+	id           int
+	item         any
+	reasonErrGen string
+	errErrGen    error
+}
+
+func NewRepoPutError(id int, item any, reasonErrGen string, errErrGen error) *RepoPutError {
+	return &RepoPutError{
+		id:           id,
+		item:         item,
+		reasonErrGen: reasonErrGen,
+		errErrGen:    errErrGen,
+	}
+}
+
+func (e *RepoPutError) Error() string {
+	return "[" + "example" + ".Repo[T]" + "] - " +
+		"Put - " + e.reasonErrGen +
+		" - args: {" +
+		"id: " + strconv.Itoa(e.id) + ", " +
+		"item: " + fmt.Sprintf("%#v", e.item) +
+		"}" + "\n" +
+		e.errErrGen.Error()
+}
+
+func (e *RepoPutError) Unwrap() error {
+	return e.errErrGen
+}
+
+func (e *RepoPutError) Is(target error) bool {
+	t, ok := target.(*RepoPutError)
+	return ok && t.reasonErrGen == e.reasonErrGen
+}
+
+func (e *RepoPutError) Reason() string {
+	return e.reasonErrGen
+}
+
+func (e *RepoPutError) Func() string {
+	return "Repo[T].Put"
+}
+
+func (e *RepoPutError) Package() string {
+	return "github.com/Bionic2113/errgen/example"
+}
+
+func (e *RepoPutError) Args() map[string]any {
+	return map[string]any{
+		"id":   e.id,
+		"item": e.item,
+	}
+}
+
+type UserUpdateNameError struct {
+	newName      string
+	reasonErrGen string
+	errErrGen    error
+}
+
+func NewUserUpdateNameError(newName string, reasonErrGen string, errErrGen error) *UserUpdateNameError {
+	return &UserUpdateNameError{
+		newName:      newName,
+		reasonErrGen: reasonErrGen,
+		errErrGen:    errErrGen,
+	}
+}
+
+func (e *UserUpdateNameError) Error() string {
+	return "[" + "example" + ".User" + "] - " +
+		"UpdateName - " + e.reasonErrGen +
+		" - args: {" +
+		"newName: " + e.newName +
+		"}" + "\n" +
+		e.errErrGen.Error()
+}
+
+func (e *UserUpdateNameError) Unwrap() error {
+	return e.errErrGen
+}
+
+func (e *UserUpdateNameError) Is(target error) bool {
+	t, ok := target.(*UserUpdateNameError)
+	return ok && t.reasonErrGen == e.reasonErrGen
+}
+
+func (e *UserUpdateNameError) Reason() string {
+	return e.reasonErrGen
+}
+
+func (e *UserUpdateNameError) Func() string {
+	return "User.UpdateName"
+}
+
+func (e *UserUpdateNameError) Package() string {
+	return "github.com/Bionic2113/errgen/example"
+}
+
+func (e *UserUpdateNameError) Args() map[string]any {
+	return map[string]any{
+		"newName": e.newName,
+	}
+}
+
+type ProcessUserError struct {
+	user         *User
+	count        int
+	reasonErrGen string
+	errErrGen    error
+}
+
+func NewProcessUserError(user *User, count int, reasonErrGen string, errErrGen error) *ProcessUserError {
+	return &ProcessUserError{
+		user:         user,
+		count:        count,
+		reasonErrGen: reasonErrGen,
+		errErrGen:    errErrGen,
+	}
+}
+
+func (e *ProcessUserError) Error() string {
+	return "[" + "example" + "] - " +
+		"ProcessUser - " + e.reasonErrGen +
+		" - args: {" +
+		"user: " + fmt.Sprintf("%#v", e.user) + ", " +
+		"count: " + strconv.Itoa(e.count) +
+		"}" + "\n" +
+		e.errErrGen.Error()
+}
+
+func (e *ProcessUserError) Unwrap() error {
+	return e.errErrGen
+}
+
+func (e *ProcessUserError) Is(target error) bool {
+	t, ok := target.(*ProcessUserError)
+	return ok && t.reasonErrGen == e.reasonErrGen
+}
+
+func (e *ProcessUserError) Reason() string {
+	return e.reasonErrGen
+}
+
+func (e *ProcessUserError) Func() string {
+	return "ProcessUser"
+}
+
+func (e *ProcessUserError) Package() string {
+	return "github.com/Bionic2113/errgen/example"
+}
+
+func (e *ProcessUserError) Args() map[string]any {
+	return map[string]any{
+		"user":  e.user,
+		"count": e.count,
+	}
+}
+
+type UserIsOlderError struct {
+	user         *User
+	count        int
+	reasonErrGen string
+	errErrGen    error
+}
+
+func NewUserIsOlderError(user *User, count int, reasonErrGen string, errErrGen error) *UserIsOlderError {
+	return &UserIsOlderError{
+		user:         user,
+		count:        count,
+		reasonErrGen: reasonErrGen,
+		errErrGen:    errErrGen,
+	}
+}
+
+func (e *UserIsOlderError) Error() string {
+	return "[" + "example" + ".User" + "] - " +
+		"IsOlder - " + e.reasonErrGen +
+		" - args: {" +
+		"user: " + fmt.Sprintf("%#v", e.user) + ", " +
+		"count: " + strconv.Itoa(e.count) +
+		"}" + "\n" +
+		e.errErrGen.Error()
+}
+
+func (e *UserIsOlderError) Unwrap() error {
+	return e.errErrGen
+}
+
+func (e *UserIsOlderError) Is(target error) bool {
+	t, ok := target.(*UserIsOlderError)
+	return ok && t.reasonErrGen == e.reasonErrGen
+}
+
+func (e *UserIsOlderError) Reason() string {
+	return e.reasonErrGen
+}
+
+func (e *UserIsOlderError) Func() string {
+	return "User.IsOlder"
+}
+
+func (e *UserIsOlderError) Package() string {
+	return "github.com/Bionic2113/errgen/example"
+}
+
+func (e *UserIsOlderError) Args() map[string]any {
+	return map[string]any{
+		"user":  e.user,
+		"count": e.count,
+	}
+}
+
+type UserIsYoungerError struct {
+	user         *User
+	count        int
+	reasonErrGen string
+	errErrGen    error
+}
+
+func NewUserIsYoungerError(user *User, count int, reasonErrGen string, errErrGen error) *UserIsYoungerError {
+	return &UserIsYoungerError{
+		user:         user,
+		count:        count,
+		reasonErrGen: reasonErrGen,
+		errErrGen:    errErrGen,
+	}
+}
+
+func (e *UserIsYoungerError) Error() string {
+	return "[" + "example" + ".User" + "] - " +
+		"IsYounger - " + e.reasonErrGen +
+		" - args: {" +
+		"user: " + fmt.Sprintf("%#v", e.user) + ", " +
+		"count: " + strconv.Itoa(e.count) +
+		"}" + "\n" +
+		e.errErrGen.Error()
+}
+
+func (e *UserIsYoungerError) Unwrap() error {
+	return e.errErrGen
+}
+
+func (e *UserIsYoungerError) Is(target error) bool {
+	t, ok := target.(*UserIsYoungerError)
+	return ok && t.reasonErrGen == e.reasonErrGen
+}
+
+func (e *UserIsYoungerError) Reason() string {
+	return e.reasonErrGen
+}
+
+func (e *UserIsYoungerError) Func() string {
+	return "User.IsYounger"
+}
+
+func (e *UserIsYoungerError) Package() string {
+	return "github.com/Bionic2113/errgen/example"
+}
+
+func (e *UserIsYoungerError) Args() map[string]any {
+	return map[string]any{
+		"user":  e.user,
+		"count": e.count,
+	}
+}
+
+type UserIsYoungerOrOlderError struct {
+	user         *User
+	count        int
+	reasonErrGen string
+	errErrGen    error
+}
+
+func NewUserIsYoungerOrOlderError(user *User, count int, reasonErrGen string, errErrGen error) *UserIsYoungerOrOlderError {
+	return &UserIsYoungerOrOlderError{
+		user:         user,
+		count:        count,
+		reasonErrGen: reasonErrGen,
+		errErrGen:    errErrGen,
+	}
+}
+
+func (e *UserIsYoungerOrOlderError) Error() string {
+	return "[" + "example" + ".User" + "] - " +
+		"IsYoungerOrOlder - " + e.reasonErrGen +
+		" - args: {" +
+		"user: " + fmt.Sprintf("%#v", e.user) + ", " +
+		"count: " + strconv.Itoa(e.count) +
+		"}" + "\n" +
+		e.errErrGen.Error()
+}
+
+func (e *UserIsYoungerOrOlderError) Unwrap() error {
+	return e.errErrGen
+}
+
+func (e *UserIsYoungerOrOlderError) Is(target error) bool {
+	t, ok := target.(*UserIsYoungerOrOlderError)
+	return ok && t.reasonErrGen == e.reasonErrGen
+}
+
+func (e *UserIsYoungerOrOlderError) Reason() string {
+	return e.reasonErrGen
+}
+
+func (e *UserIsYoungerOrOlderError) Func() string {
+	return "User.IsYoungerOrOlder"
+}
+
+func (e *UserIsYoungerOrOlderError) Package() string {
+	return "github.com/Bionic2113/errgen/example"
+}
+
+func (e *UserIsYoungerOrOlderError) Args() map[string]any {
+	return map[string]any{
+		"user":  e.user,
+		"count": e.count,
+	}
+}
+
+type UserFindNameError struct {
+	name         string
+	reasonErrGen string
+	errErrGen    error
+}
+
+func NewUserFindNameError(name string, reasonErrGen string, errErrGen error) *UserFindNameError {
+	return &UserFindNameError{
+		name:         name,
+		reasonErrGen: reasonErrGen,
+		errErrGen:    errErrGen,
+	}
+}
+
+func (e *UserFindNameError) Error() string {
+	return "[" + "example" + ".User" + "] - " +
+		"FindName - " + e.reasonErrGen +
+		" - args: {" +
+		"name: " + e.name +
+		"}" + "\n" +
+		e.errErrGen.Error()
+}
+
+func (e *UserFindNameError) Unwrap() error {
+	return e.errErrGen
+}
+
+func (e *UserFindNameError) Is(target error) bool {
+	t, ok := target.(*UserFindNameError)
+	return ok && t.reasonErrGen == e.reasonErrGen
+}
+
+func (e *UserFindNameError) Reason() string {
+	return e.reasonErrGen
+}
+
+func (e *UserFindNameError) Func() string {
+	return "User.FindName"
+}
+
+func (e *UserFindNameError) Package() string {
+	return "github.com/Bionic2113/errgen/example"
+}
+
+func (e *UserFindNameError) Args() map[string]any {
+	return map[string]any{
+		"name": e.name,
+	}
+}
+
+type UserLockError struct {
+	name         string
+	reasonErrGen string
+	errErrGen    error
+}
+
+func NewUserLockError(name string, reasonErrGen string, errErrGen error) *UserLockError {
+	return &UserLockError{
+		name:         name,
+		reasonErrGen: reasonErrGen,
+		errErrGen:    errErrGen,
+	}
+}
+
+func (e *UserLockError) Error() string {
+	return "[" + "example" + ".User" + "] - " +
+		"Lock - " + e.reasonErrGen +
+		" - args: {" +
+		"name: " + e.name +
+		"}" + "\n" +
+		e.errErrGen.Error()
+}
+
+func (e *UserLockError) Unwrap() error {
+	return e.errErrGen
+}
+
+func (e *UserLockError) Is(target error) bool {
+	t, ok := target.(*UserLockError)
+	return ok && t.reasonErrGen == e.reasonErrGen
+}
+
+func (e *UserLockError) Reason() string {
+	return e.reasonErrGen
+}
+
+func (e *UserLockError) Func() string {
+	return "User.Lock"
+}
+
+func (e *UserLockError) Package() string {
+	return "github.com/Bionic2113/errgen/example"
+}
+
+func (e *UserLockError) Args() map[string]any {
+	return map[string]any{
+		"name": e.name,
+	}
+}
+
+type UserCheckConfigError struct {
+	nothing      any
+	reasonErrGen string
+	errErrGen    error
+}
+
+func NewUserCheckConfigError(nothing any, reasonErrGen string, errErrGen error) *UserCheckConfigError {
+	return &UserCheckConfigError{
+		nothing:      nothing,
+		reasonErrGen: reasonErrGen,
+		errErrGen:    errErrGen,
+	}
+}
+
+func (e *UserCheckConfigError) Error() string {
+	return "[" + "example" + ".User" + "] - " +
+		"CheckConfig - " + e.reasonErrGen +
+		" - args: {" +
+		"nothing: " + fmt.Sprintf("%#v", e.nothing) +
+		"}" + "\n" +
+		e.errErrGen.Error()
+}
+
+func (e *UserCheckConfigError) Unwrap() error {
+	return e.errErrGen
+}
+
+func (e *UserCheckConfigError) Is(target error) bool {
+	t, ok := target.(*UserCheckConfigError)
+	return ok && t.reasonErrGen == e.reasonErrGen
+}
+
+func (e *UserCheckConfigError) Reason() string {
+	return e.reasonErrGen
+}
+
+func (e *UserCheckConfigError) Func() string {
+	return "User.CheckConfig"
+}
+
+func (e *UserCheckConfigError) Package() string {
+	return "github.com/Bionic2113/errgen/example"
+}
+
+func (e *UserCheckConfigError) Args() map[string]any {
+	return map[string]any{
+		"nothing": e.nothing,
+	}
+}
+
+type UserRenameError struct {
+	name         string
+	valuesErrGen map[string]any
+	reasonErrGen string
+	errErrGen    error
+}
+
+func NewUserRenameError(name string, valuesErrGen map[string]any, reasonErrGen string, errErrGen error) *UserRenameError {
+	return &UserRenameError{
+		name:         name,
+		valuesErrGen: valuesErrGen,
+		reasonErrGen: reasonErrGen,
+		errErrGen:    errErrGen,
+	}
+}
+
+func (e *UserRenameError) Error() string {
+	return "[" + "example" + ".User" + "] - " +
+		"Rename - " + e.reasonErrGen +
+		" - args: {" +
+		"name: " + e.name +
+		"}" + formatValuesErrGen(e.valuesErrGen) + "\n" +
+		e.errErrGen.Error()
+}
+
+func (e *UserRenameError) Unwrap() error {
+	return e.errErrGen
+}
+
+func (e *UserRenameError) Is(target error) bool {
+	t, ok := target.(*UserRenameError)
+	return ok && t.reasonErrGen == e.reasonErrGen
+}
+
+func (e *UserRenameError) Reason() string {
+	return e.reasonErrGen
+}
+
+func (e *UserRenameError) Func() string {
+	return "User.Rename"
+}
+
+func (e *UserRenameError) Package() string {
+	return "github.com/Bionic2113/errgen/example"
+}
+
+func (e *UserRenameError) Args() map[string]any {
+	args := map[string]any{
+		"name": e.name,
+	}
+	for name, v := range e.valuesErrGen {
+		args[name] = v
+	}
+
+	return args
+}
+
+type UserLoadError struct {
+	reasonErrGen string
+	errErrGen    error
+}
+
+func NewUserLoadError(reasonErrGen string, errErrGen error) *UserLoadError {
+	return &UserLoadError{
+		reasonErrGen: reasonErrGen,
+		errErrGen:    errErrGen,
+	}
+}
+
+func (e *UserLoadError) Error() string {
+	return "[" + "example" + ".User" + "] - " +
+		"Load - " + e.reasonErrGen +
+		"\n" +
+		e.errErrGen.Error()
+}
+
+func (e *UserLoadError) Unwrap() error {
+	return e.errErrGen
+}
+
+func (e *UserLoadError) Is(target error) bool {
+	t, ok := target.(*UserLoadError)
+	return ok && t.reasonErrGen == e.reasonErrGen
+}
+
+func (e *UserLoadError) Reason() string {
+	return e.reasonErrGen
+}
+
+func (e *UserLoadError) Func() string {
+	return "User.Load"
+}
+
+func (e *UserLoadError) Package() string {
+	return "github.com/Bionic2113/errgen/example"
+}
+
+func (e *UserLoadError) Args() map[string]any {
+	return map[string]any{}
+}
+
+type UserCountError struct {
+	reasonErrGen string
+	errErrGen    error
+}
+
+func NewUserCountError(reasonErrGen string, errErrGen error) *UserCountError {
+	return &UserCountError{
+		reasonErrGen: reasonErrGen,
+		errErrGen:    errErrGen,
+	}
+}
+
+func (e *UserCountError) Error() string {
+	return "[" + "example" + ".User" + "] - " +
+		"Count - " + e.reasonErrGen +
+		"\n" +
+		e.errErrGen.Error()
+}
+
+func (e *UserCountError) Unwrap() error {
+	return e.errErrGen
+}
+
+func (e *UserCountError) Is(target error) bool {
+	t, ok := target.(*UserCountError)
+	return ok && t.reasonErrGen == e.reasonErrGen
+}
+
+func (e *UserCountError) Reason() string {
+	return e.reasonErrGen
+}
+
+func (e *UserCountError) Func() string {
+	return "User.Count"
+}
+
+func (e *UserCountError) Package() string {
+	return "github.com/Bionic2113/errgen/example"
+}
+
+func (e *UserCountError) Args() map[string]any {
+	return map[string]any{}
+}
+
+// formatValuesErrGen renders values of fmt.Errorf arguments
+// kept by the wrapper sorted by their names.
+func formatValuesErrGen(values map[string]any) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	s := " - values: {"
+	for i, name := range names {
+		if i > 0 {
+			s += ", "
+		}
+		s += name + ": " + fmt.Sprintf("%#v", values[name])
+	}
+
+	return s + "}"
+}
@@ -0,0 +1,23 @@
+package example
+
+type Repo[T any] struct {
+	items map[int]T
+}
+
+func (r *Repo[T]) Get(id int) (T, error) {
+	item, ok := r.items[id]
+	if !ok {
+		return item, NewRepoGetError(id, "unknown error in Get", ErrItemNotFound)
+	}
+
+	return item, nil
+}
+
+func (r *Repo[T]) Put(id int, item T) error {
+	if r.items == nil {
+		return NewRepoPutError(id, item, "unknown error in Put", ErrRepoIsNotInitialized)
+	}
+
+	r.items[id] = item
+	return nil
+}
@@ -0,0 +1,143 @@
+package example
+
+import (
+	"database/sql"
+	"fmt"
+	"sync"
+
+	"github.com/Bionic2113/errgen/pkg/skipper"
+)
+
+// First comment
+type User struct {
+	Name string
+	Age  int
+}
+
+func (u *User) UpdateName(newName string) error {
+	if newName == "" {
+		return NewUserUpdateNameError(newName, "unknown error in UpdateName", ErrNameCannotBeEmpty)
+	}
+	u.Name = newName
+
+	return nil
+}
+
+func ProcessUser(user *User, count int) error {
+	if err := user.UpdateName("New"); err != nil {
+		return NewProcessUserError(user, count, "user.UpdateName", err)
+	}
+
+	return NewProcessUserError(user, count, "unknown error in ProcessUser", ErrProcessingFailed)
+}
+
+// Some comment
+func (u *User) IsOlder(user *User, count int) (bool, error) {
+	if user == nil {
+		return false, NewUserIsOlderError(user, count, "unknown error in IsOlder", ErrUserIsNil)
+	}
+	// Third comment
+	if u == nil {
+		return false, NewUserIsOlderError(user, count, "unknown error in IsOlder", ErrCurrentUserIsNil)
+	}
+	if u.Age > user.Age {
+		return true, nil
+	}
+	return false, nil
+}
+
+func (u *User) IsYounger(user *User, count int) (error, bool) {
+	if user == nil {
+		return NewUserIsYoungerError(user, count, "unknown error in IsYounger", ErrUserIsNil), false
+	}
+	if u == nil {
+		return NewUserIsYoungerError(user, count, "unknown error in IsYounger", ErrCurrentUserIsNil2), false
+	}
+	if u.Age < user.Age {
+		return nil, true
+	}
+	return nil, false
+}
+
+// Last commnent
+func (u *User) IsYoungerOrOlder(user *User, count int) (bool, bool, error) {
+	if user == nil {
+		return false, false, NewUserIsYoungerOrOlderError(user, count, "unknown error in IsYoungerOrOlder", ErrUserIsNil2)
+	}
+	if count > 100 {
+		return false, false, NewUserIsYoungerOrOlderError(user, count, "unknown error in IsYoungerOrOlder", ErrCountIsOver100)
+	}
+	if u == nil {
+		return false, false, NewUserIsYoungerOrOlderError(user, count, "unknown error in IsYoungerOrOlder", ErrCurrentUserIsNil)
+	}
+	if u.Age < user.Age {
+		return true, false, nil
+	}
+	if u.Age > user.Age {
+		return false, true, nil
+	}
+	return false, false, nil
+}
+
+// With DB banned
+func (u *User) FindName(db *sql.DB, name string) (string, error) {
+	if u == nil {
+		return "", NewUserFindNameError(name, "unknown error in FindName", ErrUserIsNil)
+	}
+
+	return u.Name, nil
+}
+
+// With sync banned
+func (u *User) Lock(mx *sync.Mutex, name string) (string, error) {
+	if u == nil {
+		return "", NewUserLockError(name, "unknown error in Lock", ErrUserIsNil)
+	}
+
+	return u.Name, nil
+}
+
+// With config banned
+func (u *User) CheckConfig(cfg skipper.Config, nothing any) error {
+	if u == nil {
+		return NewUserCheckConfigError(nothing, "unknown error in CheckConfig", ErrUserIsNil)
+	}
+	return nil
+}
+
+func (u *User) Rename(db *sql.DB, name string) error {
+	old := u.Name
+	if _, err := db.Exec("update users set name = $1 where name = $2", name, old); err != nil {
+		return NewUserRenameError(name, map[string]any{"old": old, "u.Age": u.Age}, "db.Exec", fmt.Errorf("rename %q (age %d) to %q: %w", old, u.Age, name, err))
+	}
+	u.Name = name
+
+	return nil
+}
+
+func (u *User) Load(db *sql.DB) (err error) {
+	rows, err := db.Query("select name from users")
+	if err != nil {
+		return NewUserLoadError("db.Query", err)
+	}
+	defer func() {
+		if err = rows.Close(); err != nil {
+			err = NewUserLoadError("rows.Close", err)
+		}
+	}()
+
+	for rows.Next() {
+		if err = rows.Scan(&u.Name); err != nil {
+			return NewUserLoadError("rows.Scan", err)
+		}
+	}
+	return rows.Err()
+}
+
+func (u *User) Count(db *sql.DB) (n int, err error) {
+	err = db.QueryRow("select count(*) from users").Scan(&n)
+	if err != nil {
+		err = NewUserCountError("db.QueryRow.Scan", err)
+	}
+	return
+}